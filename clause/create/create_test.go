@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/taosdata/tdengine_gorm/clause/create"
 	"github.com/taosdata/tdengine_gorm/clause/tests"
+	"github.com/taosdata/tdengine_gorm/clause/using"
 	"testing"
 
 	"gorm.io/gorm/clause"
@@ -74,9 +75,8 @@ func TestCreateTable(t *testing.T) {
 				})},
 				[]string{
 					"CREATE TABLE IF NOT EXISTS t_1 USING st_1(tag_int,tag_string) TAGS (?,?)",
-					"CREATE TABLE IF NOT EXISTS t_1 USING st_1(tag_string,tag_int) TAGS (?,?)",
 				},
-				[][][]interface{}{{{1, "string"}}, {{"string", 1}}},
+				[][][]interface{}{{{1, "string"}}},
 			},
 			{
 				[]clause.Interface{create.NewCreateTableClause(nil).AddTables(&create.Table{
@@ -317,6 +317,10 @@ func TestNewTable(t *testing.T) {
 		"tag_int":    1,
 		"tag_string": "string",
 	})
+	pairTable := create.NewTableWithTagPairs("t_2", true, nil, "st_1", []using.TagPair{
+		{Name: "tag_string", Value: "string"},
+		{Name: "tag_int", Value: 1},
+	})
 	sTable := create.NewSTable("st_1", true, []*create.Column{
 		{
 			Name:       "ts",
@@ -380,9 +384,17 @@ func TestNewTable(t *testing.T) {
 				})},
 				[]string{
 					"CREATE TABLE IF NOT EXISTS t_1 USING st_1(tag_int,tag_string) TAGS (?,?)",
-					"CREATE TABLE IF NOT EXISTS t_1 USING st_1(tag_string,tag_int) TAGS (?,?)",
 				},
-				[][][]interface{}{{{1, "string"}}, {{"string", 1}}},
+				[][][]interface{}{{{1, "string"}}},
+			},
+			{
+				[]clause.Interface{create.NewCreateTableClause([]*create.Table{
+					pairTable,
+				})},
+				[]string{
+					"CREATE TABLE IF NOT EXISTS t_2 USING st_1(tag_string,tag_int) TAGS (?,?)",
+				},
+				[][][]interface{}{{{"string", 1}}},
 			},
 			{
 				[]clause.Interface{create.NewCreateTableClause([]*create.Table{
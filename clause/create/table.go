@@ -1,7 +1,7 @@
 package create
 
 import (
-	"bytes"
+	"github.com/taosdata/tdengine_gorm/clause/using"
 	"gorm.io/gorm/clause"
 	"strconv"
 )
@@ -21,6 +21,7 @@ type Table struct {
 	IfNotExists bool
 	STable      string
 	Tags        map[string]interface{}
+	TagPairs    []using.TagPair
 	Column      []*Column
 	TagColumn   []*Column
 }
@@ -37,6 +38,19 @@ func NewTable(name string, ifNotExist bool, column []*Column, Stable string, tag
 	}
 }
 
+// NewTableWithTagPairs Create new common table, tags are written in the order of the pairs.
+// Tables without TagPairs write Tags sorted by name.
+func NewTableWithTagPairs(name string, ifNotExist bool, column []*Column, Stable string, tags []using.TagPair) *Table {
+	return &Table{
+		TableType:   CommonTableType,
+		Table:       name,
+		IfNotExists: ifNotExist,
+		STable:      Stable,
+		TagPairs:    tags,
+		Column:      column,
+	}
+}
+
 // NewSTable Create new sTable
 func NewSTable(name string, ifNotExists bool, column []*Column, tagColumn []*Column) *Table {
 	return &Table{
@@ -78,17 +92,15 @@ const (
 	NCharType     = "NCHAR"
 )
 
-func (c *Column) toSql() string {
-	b := bytes.NewBufferString("")
-	b.WriteString(c.Name)
-	b.WriteByte(' ')
-	b.WriteString(c.ColumnType)
+func (c *Column) build(builder clause.Builder) {
+	builder.WriteQuoted(c.Name)
+	builder.WriteByte(' ')
+	builder.WriteString(c.ColumnType)
 	if c.ColumnType == NCharType || c.ColumnType == BinaryType {
-		b.WriteByte('(')
-		b.WriteString(strconv.FormatUint(c.Length, 10))
-		b.WriteByte(')')
+		builder.WriteByte('(')
+		builder.WriteString(strconv.FormatUint(c.Length, 10))
+		builder.WriteByte(')')
 	}
-	return b.String()
 }
 
 func (CreateTable) Name() string {
@@ -108,27 +120,29 @@ func (c CreateTable) Build(builder clause.Builder) {
 		if table.IfNotExists {
 			builder.WriteString("IF NOT EXISTS ")
 		}
-		builder.WriteString(table.Table)
+		builder.WriteQuoted(table.Table)
 		if table.TableType == CommonTableType && table.STable != "" {
 			builder.WriteString(" USING ")
-			builder.WriteString(table.STable)
-			tagValueList := make([]interface{}, 0, len(table.Tags))
-			index := 0
+			builder.WriteQuoted(table.STable)
+			tagPairs := table.TagPairs
+			if len(tagPairs) == 0 {
+				tagPairs = using.SortedTagPairs(table.Tags)
+			}
+			tagValueList := make([]interface{}, 0, len(tagPairs))
 			builder.WriteByte('(')
-			for tag, tagValue := range table.Tags {
-				builder.WriteString(tag)
-				if index != len(table.Tags)-1 {
+			for i, tagPair := range tagPairs {
+				builder.WriteQuoted(tagPair.Name)
+				if i != len(tagPairs)-1 {
 					builder.WriteByte(',')
 				}
-				tagValueList = append(tagValueList, tagValue)
-				index += 1
+				tagValueList = append(tagValueList, tagPair.Value)
 			}
 			builder.WriteString(") TAGS ")
 			builder.AddVar(builder, tagValueList)
 		} else {
 			builder.WriteString(" (")
 			for i, column := range table.Column {
-				builder.WriteString(column.toSql())
+				column.build(builder)
 				if i != len(table.Column)-1 {
 					builder.WriteByte(',')
 				}
@@ -138,7 +152,7 @@ func (c CreateTable) Build(builder clause.Builder) {
 		if table.TableType == STableType {
 			builder.WriteString(" TAGS(")
 			for i, tags := range table.TagColumn {
-				builder.WriteString(tags.toSql())
+				tags.build(builder)
 				if i != len(table.TagColumn)-1 {
 					builder.WriteByte(',')
 				}
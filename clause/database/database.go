@@ -0,0 +1,190 @@
+package database
+
+import (
+	"strconv"
+
+	"gorm.io/gorm/clause"
+)
+
+// Precision is the timestamp precision of a database
+type Precision string
+
+const (
+	PrecisionMillisecond Precision = "ms"
+	PrecisionMicrosecond Precision = "us"
+	PrecisionNanosecond  Precision = "ns"
+)
+
+// UpdateMode is the UPDATE option of a database, the zero value leaves it to the server
+type UpdateMode int
+
+const (
+	// UpdateDisabled UPDATE 0, rows with an existing timestamp are discarded
+	UpdateDisabled UpdateMode = iota + 1
+	// UpdateAll UPDATE 1, rows with an existing timestamp replace the whole row
+	UpdateAll
+	// UpdatePartial UPDATE 2, rows with an existing timestamp replace the columns that are not NULL
+	UpdatePartial
+)
+
+// Value the number written after UPDATE
+func (u UpdateMode) Value() int {
+	return int(u) - 1
+}
+
+// CacheLastMode is the CACHELAST option of a database, the zero value leaves it to the server
+type CacheLastMode int
+
+const (
+	// CacheLastNone CACHELAST 0
+	CacheLastNone CacheLastMode = iota + 1
+	// CacheLastRow CACHELAST 1, cache the last row of each subtable
+	CacheLastRow
+	// CacheLastValue CACHELAST 2, cache the last non NULL value of each column
+	CacheLastValue
+	// CacheLastBoth CACHELAST 3
+	CacheLastBoth
+)
+
+// Value the number written after CACHELAST
+func (c CacheLastMode) Value() int {
+	return int(c) - 1
+}
+
+// DatabaseOptions options of CREATE DATABASE and ALTER DATABASE, zero values are not written
+type DatabaseOptions struct {
+	// Keep days to keep data
+	Keep int
+	// Days days of data in one file, TDengine 2.x
+	Days int
+	// Duration days of data in one file, replaces Days since TDengine 2.6
+	Duration  int
+	Precision Precision
+	Update    UpdateMode
+	Replica   int
+	Blocks    int
+	CacheLast CacheLastMode
+}
+
+// Build KEEP keep DAYS days ...
+func (o DatabaseOptions) Build(builder clause.Builder) {
+	writeInt := func(name string, value int) {
+		builder.WriteByte(' ')
+		builder.WriteString(name)
+		builder.WriteByte(' ')
+		builder.WriteString(strconv.Itoa(value))
+	}
+	if o.Keep > 0 {
+		writeInt("KEEP", o.Keep)
+	}
+	if o.Days > 0 {
+		writeInt("DAYS", o.Days)
+	}
+	if o.Duration > 0 {
+		writeInt("DURATION", o.Duration)
+	}
+	if o.Precision != "" {
+		builder.WriteString(" PRECISION '")
+		builder.WriteString(string(o.Precision))
+		builder.WriteByte('\'')
+	}
+	if o.Update > 0 {
+		writeInt("UPDATE", o.Update.Value())
+	}
+	if o.Replica > 0 {
+		writeInt("REPLICA", o.Replica)
+	}
+	if o.Blocks > 0 {
+		writeInt("BLOCKS", o.Blocks)
+	}
+	if o.CacheLast > 0 {
+		writeInt("CACHELAST", o.CacheLast.Value())
+	}
+}
+
+// Create CREATE DATABASE clause
+type Create struct {
+	Database    string
+	IfNotExists bool
+	Options     DatabaseOptions
+}
+
+// NewCreate Create database clause
+func NewCreate(name string, ifNotExists bool, options DatabaseOptions) Create {
+	return Create{Database: name, IfNotExists: ifNotExists, Options: options}
+}
+
+func (Create) Name() string {
+	return "CREATE DATABASE"
+}
+
+// Build CREATE DATABASE [IF NOT EXISTS] db_name [KEEP keep] [DAYS days] [PRECISION 'precision'] [UPDATE 1] ...
+func (c Create) Build(builder clause.Builder) {
+	builder.WriteString("CREATE DATABASE ")
+	if c.IfNotExists {
+		builder.WriteString("IF NOT EXISTS ")
+	}
+	builder.WriteQuoted(c.Database)
+	c.Options.Build(builder)
+}
+
+func (c Create) MergeClause(clause *clause.Clause) {
+	clause.Name = ""
+	clause.Expression = c
+}
+
+// Alter ALTER DATABASE clause, only KEEP, UPDATE, REPLICA, BLOCKS and CACHELAST can be altered
+type Alter struct {
+	Database string
+	Options  DatabaseOptions
+}
+
+// NewAlter Alter database clause
+func NewAlter(name string, options DatabaseOptions) Alter {
+	return Alter{Database: name, Options: options}
+}
+
+func (Alter) Name() string {
+	return "ALTER DATABASE"
+}
+
+// Build ALTER DATABASE db_name [KEEP keep] [UPDATE 1] ...
+func (a Alter) Build(builder clause.Builder) {
+	builder.WriteString("ALTER DATABASE ")
+	builder.WriteQuoted(a.Database)
+	a.Options.Build(builder)
+}
+
+func (a Alter) MergeClause(clause *clause.Clause) {
+	clause.Name = ""
+	clause.Expression = a
+}
+
+// Drop DROP DATABASE clause
+type Drop struct {
+	Database string
+	IfExists bool
+}
+
+// NewDrop Drop database clause
+func NewDrop(name string, ifExists bool) Drop {
+	return Drop{Database: name, IfExists: ifExists}
+}
+
+func (Drop) Name() string {
+	return "DROP DATABASE"
+}
+
+// Build DROP DATABASE [IF EXISTS] db_name
+func (d Drop) Build(builder clause.Builder) {
+	builder.WriteString("DROP DATABASE ")
+	if d.IfExists {
+		builder.WriteString("IF EXISTS ")
+	}
+	builder.WriteQuoted(d.Database)
+}
+
+func (d Drop) MergeClause(clause *clause.Clause) {
+	clause.Name = ""
+	clause.Expression = d
+}
@@ -0,0 +1,65 @@
+package database_test
+
+import (
+	"fmt"
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"github.com/taosdata/tdengine_gorm/clause/tests"
+	"testing"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestDatabase(t *testing.T) {
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{database.NewCreate("power", true, database.DatabaseOptions{})},
+				Result:  []string{"CREATE DATABASE IF NOT EXISTS power"},
+			},
+			{
+				Clauses: []clause.Interface{database.NewCreate("power", false, database.DatabaseOptions{
+					Keep:      3650,
+					Days:      10,
+					Precision: database.PrecisionMicrosecond,
+					Update:    database.UpdateDisabled,
+					Replica:   1,
+					Blocks:    6,
+					CacheLast: database.CacheLastRow,
+				})},
+				Result: []string{"CREATE DATABASE power KEEP 3650 DAYS 10 PRECISION 'us' UPDATE 0 REPLICA 1 BLOCKS 6 CACHELAST 1"},
+			},
+			{
+				Clauses: []clause.Interface{database.NewCreate("power", true, database.DatabaseOptions{
+					Duration:  10,
+					Precision: database.PrecisionNanosecond,
+					Update:    database.UpdatePartial,
+				})},
+				Result: []string{"CREATE DATABASE IF NOT EXISTS power DURATION 10 PRECISION 'ns' UPDATE 2"},
+			},
+			{
+				Clauses: []clause.Interface{database.NewAlter("power", database.DatabaseOptions{
+					Keep:      365,
+					CacheLast: database.CacheLastNone,
+				})},
+				Result: []string{"ALTER DATABASE power KEEP 365 CACHELAST 0"},
+			},
+			{
+				Clauses: []clause.Interface{database.NewDrop("power", true)},
+				Result:  []string{"DROP DATABASE IF EXISTS power"},
+			},
+			{
+				Clauses: []clause.Interface{database.NewDrop("power", false)},
+				Result:  []string{"DROP DATABASE power"},
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
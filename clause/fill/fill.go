@@ -7,6 +7,7 @@ import (
 
 type Fill struct {
 	value    float64
+	values   []interface{}
 	fillType Type
 }
 type Type string
@@ -18,15 +19,23 @@ const (
 	FillNull   Type = "NULL"
 	FillLinear Type = "LINEAR"
 	FillNext   Type = "NEXT"
+	FillNullF  Type = "NULL_F"
+	FillValueF Type = "VALUE_F"
 )
 
 // Build [FILL(fill_mod_and_val)]
 func (f Fill) Build(builder clause.Builder) {
 	builder.WriteString("(")
 	builder.WriteString(string(f.fillType))
-	if f.fillType == FillValue {
-		builder.WriteByte(',')
-		builder.WriteString(strconv.FormatFloat(f.value, 'g', -1, 64))
+	if f.fillType == FillValue || f.fillType == FillValueF {
+		if f.values == nil {
+			builder.WriteByte(',')
+			builder.WriteString(strconv.FormatFloat(f.value, 'g', -1, 64))
+		}
+		for _, value := range f.values {
+			builder.WriteByte(',')
+			builder.AddVar(builder, value)
+		}
 	}
 	builder.WriteByte(')')
 }
@@ -49,5 +58,17 @@ func SetFill(fillType Type) Fill {
 //SetValue Set fill value
 func (f Fill) SetValue(value float64) Fill {
 	f.value = value
+	f.values = nil
+	return f
+}
+
+//SetValues Set one fill value per output column, the values are bound through the builder so they can be of any column type.
+//Without values the value of SetValue is written
+func (f Fill) SetValues(values ...interface{}) Fill {
+	if len(values) == 0 {
+		f.values = nil
+		return f
+	}
+	f.values = append([]interface{}{}, values...)
 	return f
 }
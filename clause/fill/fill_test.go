@@ -27,6 +27,46 @@ func TestSetValue(t *testing.T) {
 				Result: []string{"SELECT t_1.avg(value) FROM t_1 INTERVAL(10m) FILL (VALUE,12)"},
 				Vars:   nil,
 			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(current),last(location),last(online)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+					fill.SetFill(fill.FillValue).SetValues(0, "n/a", false),
+				},
+				Result: []string{"SELECT avg(current),last(location),last(online) FROM meters INTERVAL(10m) FILL (VALUE,?,?,?)"},
+				Vars:   [][][]interface{}{{{0, "n/a", false}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(current)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+					fill.SetFill(fill.FillValueF).SetValues(1.5),
+				},
+				Result: []string{"SELECT avg(current) FROM meters INTERVAL(10m) FILL (VALUE_F,?)"},
+				Vars:   [][][]interface{}{{{1.5}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(current)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+					fill.SetFill(fill.FillNullF).SetValues(1),
+				},
+				Result: []string{"SELECT avg(current) FROM meters INTERVAL(10m) FILL (NULL_F)"},
+				Vars:   nil,
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(current)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+					fill.SetFill(fill.FillValue).SetValue(3).SetValues(),
+				},
+				Result: []string{"SELECT avg(current) FROM meters INTERVAL(10m) FILL (VALUE,3)"},
+				Vars:   nil,
+			},
 		}
 	)
 	for idx, result := range results {
@@ -0,0 +1,223 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taosdata/tdengine_gorm/clause/window"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidFunction is reported for a function call or a select list that TDengine does not accept
+var ErrInvalidFunction = errors.New("invalid function")
+
+type kind int
+
+const (
+	aggregate kind = iota
+	selector
+	// multiRow functions return several rows per group and are selected alone
+	multiRow
+	// interpolation functions are only selected with each other
+	interpolation
+)
+
+// Function a TDengine aggregate or selector call such as AVG(current) AS avg_current,
+// the column is a name, a clause.Column or an expression and the other arguments are bound through the builder
+type Function struct {
+	name   string
+	kind   kind
+	column interface{}
+	args   []interface{}
+	alias  string
+	err    error
+}
+
+// Algorithms of APERCENTILE
+const (
+	AlgorithmDefault = "default"
+	AlgorithmTDigest = "t-digest"
+)
+
+// BinType the bin_type of HISTOGRAM
+type BinType string
+
+const (
+	BinUserInput BinType = "user_input"
+	BinLinear    BinType = "linear_bin"
+	BinLog       BinType = "log_bin"
+)
+
+// Avg AVG(expr)
+func Avg(column interface{}) Function {
+	return Function{name: "AVG", column: column}
+}
+
+// Twa TWA(expr), the time weighted average
+func Twa(column interface{}) Function {
+	return Function{name: "TWA", column: column}
+}
+
+// Spread SPREAD(expr), the difference of the max and min values
+func Spread(column interface{}) Function {
+	return Function{name: "SPREAD", column: column}
+}
+
+// Apercentile APERCENTILE(expr, p [, algo_type]), p is in [0, 100] and algorithm is AlgorithmDefault or AlgorithmTDigest
+func Apercentile(column interface{}, p float64, algorithm ...string) Function {
+	f := Function{name: "APERCENTILE", column: column, args: []interface{}{p}}
+	f.err = validatePercent(f.name, p)
+	switch len(algorithm) {
+	case 0:
+	case 1:
+		if algorithm[0] != AlgorithmDefault && algorithm[0] != AlgorithmTDigest && f.err == nil {
+			f.err = fmt.Errorf("%w: unknown APERCENTILE algorithm %q", ErrInvalidFunction, algorithm[0])
+		}
+		f.args = append(f.args, algorithm[0])
+	default:
+		if f.err == nil {
+			f.err = fmt.Errorf("%w: APERCENTILE takes one algorithm", ErrInvalidFunction)
+		}
+	}
+	return f
+}
+
+// Percentile PERCENTILE(expr, p [, p1] ...), up to 10 percentiles in [0, 100]
+func Percentile(column interface{}, p ...float64) Function {
+	f := Function{name: "PERCENTILE", column: column}
+	if len(p) == 0 || len(p) > 10 {
+		f.err = fmt.Errorf("%w: PERCENTILE takes 1 to 10 percentiles, got %d", ErrInvalidFunction, len(p))
+	}
+	for _, v := range p {
+		if err := validatePercent(f.name, v); err != nil && f.err == nil {
+			f.err = err
+		}
+		f.args = append(f.args, v)
+	}
+	return f
+}
+
+// Elapsed ELAPSED(ts_primary_key [, time_unit]), the covered time in the unit, which defaults to the database precision
+func Elapsed(tsColumn interface{}, unit ...window.Duration) Function {
+	f := Function{name: "ELAPSED", column: tsColumn}
+	switch len(unit) {
+	case 0:
+	case 1:
+		f.args = append(f.args, clause.Expr{SQL: unit[0].String()})
+		if unit[0].Value == 0 {
+			f.err = fmt.Errorf("%w: ELAPSED time unit must be positive", ErrInvalidFunction)
+		}
+	default:
+		f.err = fmt.Errorf("%w: ELAPSED takes one time unit", ErrInvalidFunction)
+	}
+	return f
+}
+
+// Histogram HISTOGRAM(expr, bin_type, bin_description, normalized), bins is the JSON bin description such as "[1,3,5]"
+// or {"start": 1, "width": 2, "count": 5, "infinity": false}
+func Histogram(column interface{}, binType BinType, bins string, normalized bool) Function {
+	f := Function{name: "HISTOGRAM", kind: multiRow, column: column, args: []interface{}{string(binType), bins, normalized}}
+	if binType != BinUserInput && binType != BinLinear && binType != BinLog {
+		f.err = fmt.Errorf("%w: unknown HISTOGRAM bin type %q", ErrInvalidFunction, binType)
+	}
+	return f
+}
+
+// HyperLogLog HYPERLOGLOG(expr), the approximate count of distinct values
+func HyperLogLog(column interface{}) Function {
+	return Function{name: "HYPERLOGLOG", column: column}
+}
+
+// Mode MODE(expr), the most frequent value
+func Mode(column interface{}) Function {
+	return Function{name: "MODE", kind: selector, column: column}
+}
+
+// LastRow LAST_ROW(expr), the last row of the table or supertable
+func LastRow(column interface{}) Function {
+	return Function{name: "LAST_ROW", kind: selector, column: column}
+}
+
+// First FIRST(expr), the first non NULL value, column is "*" for the first row
+func First(column interface{}) Function {
+	return Function{name: "FIRST", kind: selector, column: column}
+}
+
+// Last LAST(expr), the last non NULL value, column is "*" for the last row
+func Last(column interface{}) Function {
+	return Function{name: "LAST", kind: selector, column: column}
+}
+
+// Top TOP(expr, k), the k largest values, k is in [1, 100]
+func Top(column interface{}, k int) Function {
+	return Function{name: "TOP", kind: multiRow, column: column, args: []interface{}{k}, err: validateK("TOP", k)}
+}
+
+// Bottom BOTTOM(expr, k), the k smallest values, k is in [1, 100]
+func Bottom(column interface{}, k int) Function {
+	return Function{name: "BOTTOM", kind: multiRow, column: column, args: []interface{}{k}, err: validateK("BOTTOM", k)}
+}
+
+// Interp INTERP(expr), the value at each timestamp of the interp.SetRange and interp.SetEvery clauses
+func Interp(column interface{}) Function {
+	return Function{name: "INTERP", kind: interpolation, column: column}
+}
+
+// As select the function as alias
+func (f Function) As(alias string) Function {
+	f.alias = alias
+	return f
+}
+
+// Validate returns the error of the arguments of the function
+func (f Function) Validate() error {
+	if f.err == nil && f.column == nil {
+		return fmt.Errorf("%w: %s needs a column", ErrInvalidFunction, f.name)
+	}
+	return f.err
+}
+
+// Build the function call, the function is checked with Validate by Select when the clause is added
+func (f Function) Build(builder clause.Builder) {
+	builder.WriteString(f.name)
+	builder.WriteByte('(')
+	switch v := f.column.(type) {
+	case string:
+		if v == "*" {
+			builder.WriteByte('*')
+		} else {
+			builder.WriteQuoted(clause.Column{Name: v})
+		}
+	case clause.Column:
+		builder.WriteQuoted(v)
+	case clause.Expression:
+		v.Build(builder)
+	}
+	for _, arg := range f.args {
+		builder.WriteByte(',')
+		if expr, ok := arg.(clause.Expression); ok {
+			expr.Build(builder)
+		} else {
+			builder.AddVar(builder, arg)
+		}
+	}
+	builder.WriteByte(')')
+	if f.alias != "" {
+		builder.WriteString(" AS ")
+		builder.WriteQuoted(f.alias)
+	}
+}
+
+func validatePercent(name string, p float64) error {
+	if p < 0 || p > 100 {
+		return fmt.Errorf("%w: %s percentile %v is not in [0, 100]", ErrInvalidFunction, name, p)
+	}
+	return nil
+}
+
+func validateK(name string, k int) error {
+	if k < 1 || k > 100 {
+		return fmt.Errorf("%w: %s k %d is not in [1, 100]", ErrInvalidFunction, name, k)
+	}
+	return nil
+}
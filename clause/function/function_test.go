@@ -0,0 +1,127 @@
+package function_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/taosdata/tdengine_gorm/clause/function"
+	"github.com/taosdata/tdengine_gorm/clause/pseudo"
+	"github.com/taosdata/tdengine_gorm/clause/tests"
+	"github.com/taosdata/tdengine_gorm/clause/window"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestFunctions(t *testing.T) {
+	from := clause.From{Tables: []clause.Table{{Name: "meters"}}}
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{
+					function.Select(
+						pseudo.WStart,
+						function.Avg("current").As("avg_current"),
+						function.Twa("current"),
+						function.Spread(clause.Column{Table: "meters", Name: "voltage"}),
+						function.HyperLogLog("location"),
+					),
+					from,
+					window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+				},
+				Result: []string{"SELECT _wstart AS window_start,AVG(current) AS avg_current,TWA(current),SPREAD(meters.voltage),HYPERLOGLOG(location) FROM meters INTERVAL(10m)"},
+				Vars:   nil,
+			},
+			{
+				Clauses: []clause.Interface{
+					function.Select(
+						function.Apercentile("current", 50, function.AlgorithmTDigest),
+						function.Percentile("current", 10, 90).As("p"),
+						function.Elapsed("ts", window.Duration{Value: 1, Unit: window.Second}),
+					),
+					from,
+				},
+				Result: []string{"SELECT APERCENTILE(current,?,?),PERCENTILE(current,?,?) AS p,ELAPSED(ts,1s) FROM meters"},
+				Vars:   [][][]interface{}{{{float64(50), "t-digest", float64(10), float64(90)}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					function.Select(function.First("*"), function.Last("current"), function.LastRow(clause.Expr{SQL: "current * ?", Vars: []interface{}{2}}), function.Mode("voltage"), pseudo.TBName),
+					from,
+				},
+				Result: []string{"SELECT FIRST(*),LAST(current),LAST_ROW(current * ?),MODE(voltage),tbname AS tb_name FROM meters"},
+				Vars:   [][][]interface{}{{{2}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					function.Select(pseudo.TBName, "ts", function.Top("current", 3).As("top_current")),
+					from,
+				},
+				Result: []string{"SELECT tbname AS tb_name,ts,TOP(current,?) AS top_current FROM meters"},
+				Vars:   [][][]interface{}{{{3}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					function.Select(function.Histogram("voltage", function.BinUserInput, "[1,3,5]", false)),
+					from,
+				},
+				Result: []string{"SELECT HISTOGRAM(voltage,?,?,?) FROM meters"},
+				Vars:   [][][]interface{}{{{"user_input", "[1,3,5]", false}}},
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestFunctionValidate(t *testing.T) {
+	for _, f := range []function.Function{
+		function.Top("current", 0),
+		function.Bottom("current", 101),
+		function.Apercentile("current", 101),
+		function.Apercentile("current", 50, "exact"),
+		function.Percentile("current"),
+		function.Percentile("current", 10, -1),
+		function.Histogram("current", "bins", "[1]", true),
+		function.Elapsed("ts", window.Duration{Unit: window.Second}),
+		function.Avg(nil),
+	} {
+		if err := f.Validate(); !errors.Is(err, function.ErrInvalidFunction) {
+			t.Errorf("%+v: expect ErrInvalidFunction got %v", f, err)
+		}
+	}
+
+	for _, columns := range [][]interface{}{
+		{function.Top("current", 3), function.Last("voltage")},
+		{function.Avg("current"), function.Bottom("current", 3)},
+		{function.Top("current", 3), function.Top("voltage", 3)},
+		{function.Histogram("current", function.BinLinear, `{"start": 0, "width": 5, "count": 4, "infinity": false}`, true), function.Spread("current")},
+	} {
+		if err := function.ValidateSelect(columns...); !errors.Is(err, function.ErrInvalidFunction) {
+			t.Errorf("%v: expect ErrInvalidFunction got %v", columns, err)
+		}
+	}
+	if err := function.ValidateSelect(function.First("current"), function.Last("current"), function.Avg("voltage"), "ts"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	db, err := gorm.Open(tests.DummyDialector{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := &gorm.Statement{DB: db.Session(&gorm.Session{}), Clauses: map[string]clause.Clause{}}
+	stmt.AddClause(function.Select(function.Top("current", 3), function.First("voltage")))
+	if !errors.Is(stmt.Error, function.ErrInvalidFunction) {
+		t.Errorf("expect ErrInvalidFunction on the statement got %v", stmt.Error)
+	}
+	if _, ok := stmt.Clauses["SELECT"]; !ok {
+		t.Errorf("expect the SELECT clause to be added")
+	}
+}
@@ -0,0 +1,63 @@
+package function
+
+import (
+	"fmt"
+
+	"github.com/taosdata/tdengine_gorm/clause/internal/statement"
+	"github.com/taosdata/tdengine_gorm/clause/pseudo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Select SELECT clause like pseudo.Select, the select list is checked with ValidateSelect when the clause is added
+func Select(columns ...interface{}) SelectClause {
+	return SelectClause{Select: pseudo.Select(columns...), columns: columns}
+}
+
+// SelectClause a SELECT clause that checks its select list when it is added to a statement
+type SelectClause struct {
+	clause.Select
+	columns []interface{}
+}
+
+// ModifyStatement adds the SELECT clause to stmt, a select list that fails ValidateSelect is reported with AddError of stmt
+func (s SelectClause) ModifyStatement(stmt *gorm.Statement) {
+	statement.AddClause(stmt, s.Select, ValidateSelect(s.columns...))
+}
+
+// ValidateSelect checks the functions of a select list with Validate and against the rules of TDengine:
+// TOP, BOTTOM and HISTOGRAM return several rows per group and cannot be selected with other functions,
+// INTERP can only be selected with other INTERP calls.
+func ValidateSelect(columns ...interface{}) error {
+	var functions []Function
+	for _, column := range columns {
+		if f, ok := column.(Function); ok {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+			functions = append(functions, f)
+		}
+	}
+	for _, f := range functions {
+		if f.kind != interpolation {
+			continue
+		}
+		for _, other := range functions {
+			if other.kind != interpolation {
+				return fmt.Errorf("%w: %s cannot be selected with %s", ErrInvalidFunction, f.name, other.name)
+			}
+		}
+	}
+	for _, f := range functions {
+		if f.kind != multiRow || len(functions) == 1 {
+			continue
+		}
+		for _, other := range functions {
+			if other.name != f.name || other.kind != f.kind {
+				return fmt.Errorf("%w: %s cannot be selected with %s", ErrInvalidFunction, f.name, other.name)
+			}
+		}
+		return fmt.Errorf("%w: %s can be selected once", ErrInvalidFunction, f.name)
+	}
+	return nil
+}
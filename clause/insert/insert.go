@@ -0,0 +1,100 @@
+package insert
+
+import (
+	"github.com/taosdata/tdengine_gorm/clause/using"
+	"gorm.io/gorm/clause"
+)
+
+// MultiTable insert rows of several tables in one statement
+// INSERT INTO tb1 [USING stb(tag_names) TAGS(tag_values)] (columns) VALUES (values) [tb2 ...]
+type MultiTable struct {
+	tables []*Table
+}
+
+// Table rows of one table, the table is created from Using when it does not exist
+type Table struct {
+	Table   string
+	Using   *using.Using
+	Columns []string
+	Values  [][]interface{}
+}
+
+// NewTable rows of an existing table
+func NewTable(name string, columns []string, values [][]interface{}) *Table {
+	return &Table{
+		Table:   name,
+		Columns: columns,
+		Values:  values,
+	}
+}
+
+// NewTableUsing rows of a subtable, the subtable is created with the tags when it does not exist
+func NewTableUsing(name string, sTable string, tags []using.TagPair, columns []string, values [][]interface{}) *Table {
+	u := using.SetUsingTagPairs(sTable, tags...)
+	return &Table{
+		Table:   name,
+		Using:   &u,
+		Columns: columns,
+		Values:  values,
+	}
+}
+
+// NewMultiTable multi table insert clause
+func NewMultiTable(tables ...*Table) MultiTable {
+	return MultiTable{tables: tables}
+}
+
+// AddTables add tables to clause
+func (m MultiTable) AddTables(tables ...*Table) MultiTable {
+	m.tables = append(m.tables[:len(m.tables):len(m.tables)], tables...)
+	return m
+}
+
+// Tables tables of the clause
+func (m MultiTable) Tables() []*Table {
+	return m.tables
+}
+
+func (MultiTable) Name() string {
+	return "INSERT"
+}
+
+func (m MultiTable) Build(builder clause.Builder) {
+	builder.WriteString("INSERT INTO")
+	for _, table := range m.tables {
+		builder.WriteByte(' ')
+		table.Build(builder)
+	}
+}
+
+// Build tb [USING stb(tag_names) TAGS(tag_values)] (columns) VALUES (values)
+func (t *Table) Build(builder clause.Builder) {
+	builder.WriteQuoted(t.Table)
+	if t.Using != nil {
+		builder.WriteByte(' ')
+		t.Using.Build(builder)
+	}
+	if len(t.Columns) > 0 {
+		builder.WriteString(" (")
+		for i, column := range t.Columns {
+			if i > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteQuoted(clause.Column{Name: column})
+		}
+		builder.WriteByte(')')
+	}
+	builder.WriteString(" VALUES ")
+	for i, value := range t.Values {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.AddVar(builder, value)
+	}
+}
+
+// MergeClause merge INSERT by clauses
+func (m MultiTable) MergeClause(clause *clause.Clause) {
+	clause.Name = ""
+	clause.Expression = m
+}
@@ -0,0 +1,47 @@
+package insert_test
+
+import (
+	"fmt"
+	"github.com/taosdata/tdengine_gorm/clause/insert"
+	"github.com/taosdata/tdengine_gorm/clause/tests"
+	"github.com/taosdata/tdengine_gorm/clause/using"
+	"testing"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestMultiTable(t *testing.T) {
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{
+					insert.NewMultiTable(insert.NewTable("d1001", []string{"ts", "current"}, [][]interface{}{{1, 10.2}, {2, 10.3}})),
+				},
+				Result: []string{"INSERT INTO d1001 (ts,current) VALUES (?,?),(?,?)"},
+				Vars:   [][][]interface{}{{{1, 10.2, 2, 10.3}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					insert.NewMultiTable(
+						insert.NewTableUsing("d1001", "meters", []using.TagPair{{Name: "location", Value: "SF"}, {Name: "group_id", Value: 2}},
+							[]string{"ts", "current"}, [][]interface{}{{1, 10.2}}),
+					).AddTables(
+						insert.NewTable("d1002", []string{"ts", "current"}, [][]interface{}{{1, 11.5}, {2, 11.6}}),
+						insert.NewTable("d1003", nil, [][]interface{}{{1, 12.1}}),
+					),
+				},
+				Result: []string{"INSERT INTO d1001 USING meters(location,group_id) TAGS(?,?) (ts,current) VALUES (?,?) d1002 (ts,current) VALUES (?,?),(?,?) d1003 VALUES (?,?)"},
+				Vars:   [][][]interface{}{{{"SF", 2, 1, 10.2, 1, 11.5, 2, 11.6, 1, 12.1}}},
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package statement
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddClause merges c into the clauses of stmt like gorm.Statement.AddClause, which calls ModifyStatement
+// of the clauses that check themselves instead, and reports err of the check with AddError of stmt
+func AddClause(stmt *gorm.Statement, c clause.Interface, err error) {
+	if err != nil {
+		_ = stmt.AddError(err)
+	}
+	name := c.Name()
+	merged := stmt.Clauses[name]
+	merged.Name = name
+	c.MergeClause(&merged)
+	stmt.Clauses[name] = merged
+}
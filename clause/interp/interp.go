@@ -0,0 +1,100 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/internal/statement"
+	"github.com/taosdata/tdengine_gorm/clause/window"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidInterp is reported for a RANGE or EVERY clause that TDengine does not accept
+var ErrInvalidInterp = errors.New("invalid interp")
+
+//[RANGE(start_ts, end_ts)]
+//[EVERY(every_val)]
+
+type Range struct {
+	start time.Time
+	end   time.Time
+}
+
+// SetRange create a RANGE clause of INTERP, the timestamps are bound through the builder
+func SetRange(start, end time.Time) Range {
+	return Range{start: start, end: end}
+}
+
+// Validate checks that the range does not end before it starts
+func (r Range) Validate() error {
+	if r.end.Before(r.start) {
+		return fmt.Errorf("%w: RANGE ends at %s before it starts at %s", ErrInvalidInterp, r.end, r.start)
+	}
+	return nil
+}
+
+// ModifyStatement adds the range to stmt, a range that fails Validate is reported with AddError of stmt
+func (r Range) ModifyStatement(stmt *gorm.Statement) {
+	statement.AddClause(stmt, r, r.Validate())
+}
+
+// Build RANGE(start_ts, end_ts)
+func (r Range) Build(builder clause.Builder) {
+	builder.WriteString("RANGE(")
+	builder.AddVar(builder, r.start)
+	builder.WriteByte(',')
+	builder.AddVar(builder, r.end)
+	builder.WriteByte(')')
+}
+
+func (r Range) Name() string {
+	return "RANGE"
+}
+
+func (r Range) MergeClause(c *clause.Clause) {
+	c.Name = ""
+	c.Expression = r
+}
+
+type Every struct {
+	duration window.Duration
+}
+
+// SetEvery create an EVERY clause of INTERP, the interval of the interpolated rows
+func SetEvery(duration window.Duration) Every {
+	return Every{duration: duration}
+}
+
+// Validate checks that the duration is positive and of a fixed length, natural months and years are not accepted
+func (e Every) Validate() error {
+	if e.duration.Value == 0 {
+		return fmt.Errorf("%w: EVERY needs a positive duration", ErrInvalidInterp)
+	}
+	if _, ok := e.duration.TimeDuration(); !ok {
+		return fmt.Errorf("%w: EVERY cannot use %s", ErrInvalidInterp, e.duration)
+	}
+	return nil
+}
+
+// ModifyStatement adds the interval to stmt, an interval that fails Validate is reported with AddError of stmt
+func (e Every) ModifyStatement(stmt *gorm.Statement) {
+	statement.AddClause(stmt, e, e.Validate())
+}
+
+// Build EVERY(every_val)
+func (e Every) Build(builder clause.Builder) {
+	builder.WriteString("EVERY(")
+	builder.WriteString(e.duration.String())
+	builder.WriteByte(')')
+}
+
+func (e Every) Name() string {
+	return "EVERY"
+}
+
+func (e Every) MergeClause(c *clause.Clause) {
+	c.Name = ""
+	c.Expression = e
+}
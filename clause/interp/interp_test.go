@@ -0,0 +1,90 @@
+package interp_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/fill"
+	"github.com/taosdata/tdengine_gorm/clause/function"
+	"github.com/taosdata/tdengine_gorm/clause/interp"
+	"github.com/taosdata/tdengine_gorm/clause/partition"
+	"github.com/taosdata/tdengine_gorm/clause/pseudo"
+	"github.com/taosdata/tdengine_gorm/clause/tests"
+	"github.com/taosdata/tdengine_gorm/clause/window"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestInterp(t *testing.T) {
+	start := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{
+					function.Select(pseudo.IRowTS, function.Interp("current")),
+					clause.From{Tables: []clause.Table{{Name: "d1001"}}},
+					interp.SetRange(start, end),
+					interp.SetEvery(window.Duration{Value: 1, Unit: window.Second}),
+					fill.SetFill(fill.FillLinear),
+				},
+				Result: []string{"SELECT _irowts AS interp_ts,INTERP(current) FROM d1001 RANGE(?,?) EVERY(1s) FILL (LINEAR)"},
+				Vars:   [][][]interface{}{{{start, end}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					function.Select(pseudo.TBName, pseudo.IRowTS, function.Interp("current").As("current")),
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					partition.SetPartition(pseudo.TBName),
+					interp.SetRange(start, end),
+					interp.SetEvery(window.Duration{Value: 500, Unit: window.Millisecond}),
+					fill.SetFill(fill.FillPrev),
+				},
+				Result: []string{"SELECT tbname AS tb_name,_irowts AS interp_ts,INTERP(current) AS current FROM meters PARTITION BY tbname RANGE(?,?) EVERY(500a) FILL (PREV)"},
+				Vars:   [][][]interface{}{{{start, end}}},
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestInterpValidate(t *testing.T) {
+	start := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	if err := interp.SetRange(start, start.Add(-time.Second)).Validate(); !errors.Is(err, interp.ErrInvalidInterp) {
+		t.Errorf("expect ErrInvalidInterp got %v", err)
+	}
+	if err := interp.SetRange(start, start).Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, d := range []window.Duration{{Unit: window.Second}, {Value: 1, Unit: window.Month}, {Value: 1, Unit: window.Year}} {
+		if err := interp.SetEvery(d).Validate(); !errors.Is(err, interp.ErrInvalidInterp) {
+			t.Errorf("%s: expect ErrInvalidInterp got %v", d, err)
+		}
+	}
+	if err := function.ValidateSelect(function.Interp("current"), function.Avg("voltage")); !errors.Is(err, function.ErrInvalidFunction) {
+		t.Errorf("expect ErrInvalidFunction got %v", err)
+	}
+
+	db, err := gorm.Open(tests.DummyDialector{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := &gorm.Statement{DB: db.Session(&gorm.Session{}), Clauses: map[string]clause.Clause{}}
+	stmt.AddClause(interp.SetEvery(window.Duration{Value: 1, Unit: window.Month}))
+	if !errors.Is(stmt.Error, interp.ErrInvalidInterp) {
+		t.Errorf("expect ErrInvalidInterp on the statement got %v", stmt.Error)
+	}
+	if _, ok := stmt.Clauses["EVERY"]; !ok {
+		t.Errorf("expect the EVERY clause to be added")
+	}
+}
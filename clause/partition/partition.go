@@ -0,0 +1,57 @@
+package partition
+
+import (
+	"strings"
+
+	"github.com/taosdata/tdengine_gorm/clause/pseudo"
+	"gorm.io/gorm/clause"
+)
+
+// TBName partitions a supertable by subtable, it is the pseudo-column pseudo.TBName
+var TBName = pseudo.TBName
+
+// Partition [PARTITION BY part_list]
+type Partition struct {
+	Columns []interface{}
+}
+
+// Name PARTITION BY clause name
+func (p Partition) Name() string {
+	return "PARTITION BY"
+}
+
+// Build PARTITION BY clause, names are quoted as columns and expressions are built with their vars
+func (p Partition) Build(builder clause.Builder) {
+	for i, column := range p.Columns {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		switch v := column.(type) {
+		case string:
+			if strings.EqualFold(v, TBName.Name) {
+				builder.WriteString(v)
+			} else {
+				builder.WriteQuoted(clause.Column{Name: v})
+			}
+		case clause.Column:
+			builder.WriteQuoted(v)
+		case clause.Expression:
+			v.Build(builder)
+		default:
+			builder.AddVar(builder, v)
+		}
+	}
+}
+
+// MergeClause merge PARTITION BY by clauses, the columns are appended
+func (p Partition) MergeClause(c *clause.Clause) {
+	if v, ok := c.Expression.(Partition); ok {
+		p.Columns = append(v.Columns[:len(v.Columns):len(v.Columns)], p.Columns...)
+	}
+	c.Expression = p
+}
+
+// SetPartition Partition clause, columns are column or tag names, clause.Column, TBName or expressions such as gorm.Expr
+func SetPartition(columns ...interface{}) Partition {
+	return Partition{Columns: columns}
+}
@@ -0,0 +1,47 @@
+package partition_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/taosdata/tdengine_gorm/clause/partition"
+	"github.com/taosdata/tdengine_gorm/clause/tests"
+	"github.com/taosdata/tdengine_gorm/clause/window"
+	"gorm.io/gorm/clause"
+)
+
+func TestSetPartition(t *testing.T) {
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(current)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					partition.SetPartition(partition.TBName),
+					window.SetInterval(window.Duration{Value: 1, Unit: window.Minute}),
+				},
+				Result: []string{"SELECT avg(current) FROM meters PARTITION BY tbname INTERVAL(1m)"},
+				Vars:   nil,
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(current)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					partition.SetPartition("location", "TBNAME"),
+					partition.SetPartition(clause.Column{Table: "meters", Name: "group_id"}, clause.Expr{SQL: "current > ?", Vars: []interface{}{10}}),
+				},
+				Result: []string{"SELECT avg(current) FROM meters PARTITION BY location,TBNAME,meters.group_id,current > ?"},
+				Vars:   [][][]interface{}{{{10}}},
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
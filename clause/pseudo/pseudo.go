@@ -0,0 +1,74 @@
+package pseudo
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// Pseudo-columns, they are raw columns so they are never quoted and can be used in clause.Select,
+// the where expressions such as clause.Gte, clause.GroupBy and clause.OrderByColumn
+var (
+	WStart    = clause.Column{Name: "_wstart", Raw: true}
+	WEnd      = clause.Column{Name: "_wend", Raw: true}
+	WDuration = clause.Column{Name: "_wduration", Raw: true}
+	QStart    = clause.Column{Name: "_qstart", Raw: true}
+	QEnd      = clause.Column{Name: "_qend", Raw: true}
+	TBName    = clause.Column{Name: "tbname", Raw: true}
+	IRowTS    = clause.Column{Name: "_irowts", Raw: true}
+)
+
+// Aliases the pseudo-columns are selected as by Select,
+// the default naming strategy maps them onto the fields WindowStart, WindowEnd, WindowDuration, QueryStart, QueryEnd, TBName and InterpTS
+const (
+	WindowStart    = "window_start"
+	WindowEnd      = "window_end"
+	WindowDuration = "window_duration"
+	QueryStart     = "query_start"
+	QueryEnd       = "query_end"
+	TableName      = "tb_name"
+	InterpTS       = "interp_ts"
+)
+
+var aliases = map[string]string{
+	WStart.Name:    WindowStart,
+	WEnd.Name:      WindowEnd,
+	WDuration.Name: WindowDuration,
+	QStart.Name:    QueryStart,
+	QEnd.Name:      QueryEnd,
+	TBName.Name:    TableName,
+	IRowTS.Name:    InterpTS,
+}
+
+// As select column as alias
+func As(column clause.Column, alias string) clause.Column {
+	column.Alias = alias
+	return column
+}
+
+// Select SELECT clause, columns are clause.Column, raw strings such as "avg(current)" or expressions.
+// Pseudo-columns without an alias are selected as their default alias
+func Select(columns ...interface{}) clause.Select {
+	return clause.Select{Expression: selectList(columns)}
+}
+
+type selectList []interface{}
+
+func (list selectList) Build(builder clause.Builder) {
+	for i, column := range list {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		switch v := column.(type) {
+		case string:
+			builder.WriteString(v)
+		case clause.Column:
+			if alias, ok := aliases[v.Name]; ok && v.Raw && v.Table == "" && v.Alias == "" {
+				v.Alias = alias
+			}
+			builder.WriteQuoted(v)
+		case clause.Expression:
+			v.Build(builder)
+		default:
+			builder.AddVar(builder, v)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package pseudo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/pseudo"
+	"github.com/taosdata/tdengine_gorm/clause/tests"
+	"github.com/taosdata/tdengine_gorm/clause/window"
+	"gorm.io/gorm/clause"
+)
+
+func TestPseudoColumns(t *testing.T) {
+	start := time.Date(2021, 8, 11, 0, 0, 0, 0, time.UTC)
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{
+					pseudo.Select(pseudo.WStart, pseudo.WEnd, pseudo.WDuration, "avg(current)"),
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					clause.Where{Exprs: []clause.Expression{clause.Gte{Column: pseudo.QStart, Value: start}}},
+					window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+					clause.OrderBy{Columns: []clause.OrderByColumn{{Column: pseudo.WStart, Desc: true}}},
+				},
+				Result: []string{"SELECT _wstart AS window_start,_wend AS window_end,_wduration AS window_duration,avg(current) FROM meters WHERE _qstart >= ? INTERVAL(10m) ORDER BY _wstart DESC"},
+				Vars:   [][][]interface{}{{{start}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					pseudo.Select(pseudo.As(pseudo.TBName, "device"), pseudo.QStart, pseudo.QEnd, clause.Expr{SQL: "count(*) + ?", Vars: []interface{}{1}}),
+					clause.From{Tables: []clause.Table{{Name: "meters"}}},
+					clause.Where{Exprs: []clause.Expression{clause.Eq{Column: pseudo.TBName, Value: "d1001"}}},
+					clause.GroupBy{Columns: []clause.Column{pseudo.TBName}},
+				},
+				Result: []string{"SELECT tbname AS device,_qstart AS query_start,_qend AS query_end,count(*) + ? FROM meters WHERE tbname = ? GROUP BY tbname"},
+				Vars:   [][][]interface{}{{{1, "d1001"}}},
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
@@ -1,39 +1,78 @@
 package using
 
 import (
+	"sort"
+
 	"gorm.io/gorm/clause"
 )
 
 type Using struct {
 	sTable   string
-	tagParis map[string]interface{}
+	tagPairs []TagPair
+}
+
+// TagPair tag name and value, tags are written in the order of the pairs
+type TagPair struct {
+	Name  string
+	Value interface{}
+}
+
+// SortedTagPairs tag pairs of a tag map sorted by name
+func SortedTagPairs(tags map[string]interface{}) []TagPair {
+	pairs := make([]TagPair, 0, len(tags))
+	for tagName, tagValue := range tags {
+		pairs = append(pairs, TagPair{Name: tagName, Value: tagValue})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Name < pairs[j].Name
+	})
+	return pairs
 }
 
 func (i Using) Build(builder clause.Builder) {
 	builder.WriteString("USING ")
-	builder.WriteString(i.sTable)
-	var tagNameList = make([]string, 0, len(i.tagParis))
-	var tagValueList = make([]interface{}, 0, len(i.tagParis))
-	for tagName, tagValue := range i.tagParis {
-		tagNameList = append(tagNameList, tagName)
-		tagValueList = append(tagValueList, tagValue)
+	builder.WriteQuoted(i.sTable)
+	builder.WriteByte('(')
+	var tagValueList = make([]interface{}, 0, len(i.tagPairs))
+	for idx, pair := range i.tagPairs {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(pair.Name)
+		tagValueList = append(tagValueList, pair.Value)
 	}
-	builder.AddVar(builder, tagNameList)
-	builder.WriteString(" TAGS")
+	builder.WriteString(") TAGS")
 	builder.AddVar(builder, tagValueList)
 }
 
-//SetUsing Using clause
+//SetUsing Using clause, tags are written sorted by name
 func SetUsing(sTable string, tags map[string]interface{}) Using {
+	return SetUsingTagPairs(sTable, SortedTagPairs(tags)...)
+}
+
+// SetUsingTagPairs Using clause, tags are written in the order of the pairs
+func SetUsingTagPairs(sTable string, tags ...TagPair) Using {
 	return Using{
 		sTable:   sTable,
-		tagParis: tags,
+		tagPairs: tags,
 	}
 }
 
-//ADDTagPair add tag pair to using clause
+//ADDTagPair add tag pair to using clause, an existing tag with the same name is replaced
 func (i Using) ADDTagPair(tagName string, tagValue interface{}) Using {
-	i.tagParis[tagName] = tagValue
+	pairs := make([]TagPair, 0, len(i.tagPairs)+1)
+	replaced := false
+	for _, pair := range i.tagPairs {
+		if pair.Name == tagName {
+			pair.Value = tagValue
+			replaced = true
+		}
+		pairs = append(pairs, pair)
+	}
+	if !replaced {
+		pairs = append(pairs, TagPair{Name: tagName, Value: tagValue})
+	}
+	i.tagPairs = pairs
 	return i
 }
 
@@ -45,3 +84,13 @@ func (i Using) MergeClause(c *clause.Clause) {
 	c.Name = ""
 	c.Expression = i
 }
+
+// STable super table name
+func (i Using) STable() string {
+	return i.sTable
+}
+
+// TagPairs tag pairs in the order they are written
+func (i Using) TagPairs() []TagPair {
+	return i.tagPairs
+}
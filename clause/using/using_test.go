@@ -23,9 +23,33 @@ func TestSetValue(t *testing.T) {
 					}).ADDTagPair("tag2", "string"),
 				},
 				Result: []string{
-					"INSERT INTO tb USING stb(?,?) TAGS(?,?)",
+					"INSERT INTO tb USING stb(tag1,tag2) TAGS(?,?)",
 				},
-				Vars: [][][]interface{}{{{"tag1", "tag2", 1, "string"}, {"tag2", "tag1", "string", 1}}},
+				Vars: [][][]interface{}{{{1, "string"}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Insert{Table: clause.Table{Name: "tb"}},
+					using.SetUsing("stb", map[string]interface{}{
+						"tag2": "string",
+						"tag1": 1,
+						"tag3": 2.5,
+					}).ADDTagPair("tag2", "replaced"),
+				},
+				Result: []string{
+					"INSERT INTO tb USING stb(tag1,tag2,tag3) TAGS(?,?,?)",
+				},
+				Vars: [][][]interface{}{{{1, "replaced", 2.5}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Insert{Table: clause.Table{Name: "tb"}},
+					using.SetUsingTagPairs("stb", using.TagPair{Name: "tag2", Value: "string"}, using.TagPair{Name: "tag1", Value: 1}),
+				},
+				Result: []string{
+					"INSERT INTO tb USING stb(tag2,tag1) TAGS(?,?)",
+				},
+				Vars: [][][]interface{}{{{"string", 1}}},
 			},
 		}
 	)
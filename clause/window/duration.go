@@ -2,14 +2,17 @@ package window
 
 import (
 	"errors"
+	"math"
+	"math/big"
 	"strconv"
 	"time"
 )
 
 type UnitType string
 
-//u(微秒)、a(毫秒)、s(秒)、m(分)、h(小时)、d(天)、w(周) n(自然月) 和 y(自然年)
+//b(纳秒)、u(微秒)、a(毫秒)、s(秒)、m(分)、h(小时)、d(天)、w(周) n(自然月) 和 y(自然年)
 const (
+	Nanosecond  UnitType = "b"
 	Microsecond UnitType = "u"
 	Millisecond UnitType = "a"
 	Second      UnitType = "s"
@@ -22,6 +25,7 @@ const (
 )
 
 var durationMap = map[UnitType]struct{}{
+	Nanosecond:  {},
 	Microsecond: {},
 	Millisecond: {},
 	Second:      {},
@@ -38,13 +42,53 @@ type Duration struct {
 	Unit  UnitType
 }
 
+// fixedUnits units of a fixed length, from the largest
+var fixedUnits = []struct {
+	unit     UnitType
+	duration time.Duration
+}{
+	{Week, 7 * 24 * time.Hour},
+	{Day, 24 * time.Hour},
+	{Hour, time.Hour},
+	{Minute, time.Minute},
+	{Second, time.Second},
+	{Millisecond, time.Millisecond},
+	{Microsecond, time.Microsecond},
+	{Nanosecond, time.Nanosecond},
+}
+
+// ErrIncomparable is returned when comparing a natural month or year with a fixed length duration
+var ErrIncomparable = errors.New("natural month and year durations cannot be compared with fixed length durations")
+
+// ErrNanosecondDuration is returned by NewDurationFromTimeDuration for a duration that is not a whole number of microseconds
+var ErrNanosecondDuration = errors.New("duration needs the nanosecond unit b, use NewNanosecondDurationFromTimeDuration for a nanosecond database")
+
+//NewDurationFromTimeDuration create a duration in the largest unit down to microseconds that represents duration exactly
 func NewDurationFromTimeDuration(duration time.Duration) (*Duration, error) {
+	d, err := NewNanosecondDurationFromTimeDuration(duration)
+	if err == nil && d.Unit == Nanosecond {
+		return nil, ErrNanosecondDuration
+	}
+	return d, err
+}
+
+//NewNanosecondDurationFromTimeDuration create a duration in the largest unit that represents duration exactly,
+//durations that are not a whole number of microseconds are in the unit b that needs a database of nanosecond precision
+func NewNanosecondDurationFromTimeDuration(duration time.Duration) (*Duration, error) {
 	if duration <= 0 {
 		return nil, errors.New("duration does not allow negative numbers")
 	}
+	// nanoseconds, the last unit, divide every duration
+	fixed := fixedUnits[len(fixedUnits)-1]
+	for _, unit := range fixedUnits[:len(fixedUnits)-1] {
+		if duration%unit.duration == 0 {
+			fixed = unit
+			break
+		}
+	}
 	return &Duration{
-		Value: uint64(duration.Microseconds()),
-		Unit:  Microsecond,
+		Value: uint64(duration / fixed.duration),
+		Unit:  fixed.unit,
 	}, nil
 }
 
@@ -67,3 +111,64 @@ func ParseDuration(durationString string) (*Duration, error) {
 		Unit:  unit,
 	}, nil
 }
+
+//String duration as written in SQL, such as 5m
+func (d Duration) String() string {
+	return strconv.FormatUint(d.Value, 10) + string(d.Unit)
+}
+
+//TimeDuration convert to time.Duration, false for natural months and years and for durations that overflow time.Duration
+func (d Duration) TimeDuration() (time.Duration, bool) {
+	for _, fixed := range fixedUnits {
+		if fixed.unit == d.Unit {
+			if d.Value > uint64(math.MaxInt64/fixed.duration) {
+				return 0, false
+			}
+			return time.Duration(d.Value) * fixed.duration, true
+		}
+	}
+	return 0, false
+}
+
+//Compare returns -1, 0 or 1 when d is shorter than, as long as or longer than o.
+//Natural months and years are only comparable with each other, otherwise ErrIncomparable is returned.
+func (d Duration) Compare(o Duration) (int, error) {
+	dNatural, dLength := d.length()
+	oNatural, oLength := o.length()
+	if dLength == nil || oLength == nil {
+		return 0, errors.New("unit not valid")
+	}
+	if dNatural != oNatural {
+		return 0, ErrIncomparable
+	}
+	return dLength.Cmp(oLength), nil
+}
+
+//Equal reports whether d and o are comparable and of the same length, 60s equals 1m
+func (d Duration) Equal(o Duration) bool {
+	c, err := d.Compare(o)
+	return err == nil && c == 0
+}
+
+//Less reports whether d and o are comparable and d is shorter than o
+func (d Duration) Less(o Duration) bool {
+	c, err := d.Compare(o)
+	return err == nil && c < 0
+}
+
+// length in nanoseconds, or in months for natural durations
+func (d Duration) length() (natural bool, length *big.Int) {
+	value := new(big.Int).SetUint64(d.Value)
+	switch d.Unit {
+	case Month:
+		return true, value
+	case Year:
+		return true, value.Mul(value, big.NewInt(12))
+	}
+	for _, fixed := range fixedUnits {
+		if fixed.unit == d.Unit {
+			return false, value.Mul(value, big.NewInt(int64(fixed.duration)))
+		}
+	}
+	return false, nil
+}
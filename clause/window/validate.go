@@ -0,0 +1,85 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidWindow is reported for a window that TDengine does not accept
+var ErrInvalidWindow = errors.New("invalid window")
+
+// Validate checks the window against the rules of TDengine:
+// durations are positive, natural months and years are only used by INTERVAL and SLIDING,
+// OFFSET is smaller than INTERVAL and SLIDING is not larger than INTERVAL, in the same kind of unit,
+// EVENT_WINDOW has both conditions and the sliding of COUNT_WINDOW is not larger than its count.
+func (sc Window) Validate() error {
+	if sc.err != nil {
+		return sc.err
+	}
+	switch sc.windowType {
+	case SESSION:
+		if sc.tsColumn == "" {
+			return fmt.Errorf("%w: SESSION needs a timestamp column", ErrInvalidWindow)
+		}
+		return validateDuration("SESSION", sc.duration, false)
+	case STATE:
+		if sc.stateColumn == "" {
+			return fmt.Errorf("%w: STATE_WINDOW needs a column", ErrInvalidWindow)
+		}
+		return sc.validateTrueFor()
+	case INTERVAL:
+		if err := validateDuration("INTERVAL", sc.duration, true); err != nil {
+			return err
+		}
+		if sc.offset != nil {
+			if err := validateDuration("OFFSET", sc.offset, false); err != nil {
+				return err
+			}
+			// a fixed OFFSET of a natural INTERVAL is checked by the server
+			if c, err := sc.offset.Compare(*sc.duration); err == nil && c >= 0 {
+				return fmt.Errorf("%w: OFFSET %s must be smaller than INTERVAL %s", ErrInvalidWindow, sc.offset, sc.duration)
+			}
+		}
+		if sc.sliding != nil {
+			if err := validateDuration("SLIDING", sc.sliding, true); err != nil {
+				return err
+			}
+			if c, err := sc.sliding.Compare(*sc.duration); err != nil || c > 0 {
+				return fmt.Errorf("%w: SLIDING %s must not be larger than INTERVAL %s", ErrInvalidWindow, sc.sliding, sc.duration)
+			}
+		}
+	case EVENT:
+		if sc.start == nil || sc.end == nil {
+			return fmt.Errorf("%w: EVENT_WINDOW needs START WITH and END WITH conditions", ErrInvalidWindow)
+		}
+		return sc.validateTrueFor()
+	case COUNT:
+		if sc.count == 0 {
+			return fmt.Errorf("%w: COUNT_WINDOW needs a positive count", ErrInvalidWindow)
+		}
+		if sc.countSlide > sc.count {
+			return fmt.Errorf("%w: COUNT_WINDOW sliding %d must not be larger than count %d", ErrInvalidWindow, sc.countSlide, sc.count)
+		}
+	}
+	return nil
+}
+
+func (sc Window) validateTrueFor() error {
+	if sc.trueFor == nil {
+		return nil
+	}
+	return validateDuration("TRUE_FOR", sc.trueFor, false)
+}
+
+func validateDuration(name string, d *Duration, natural bool) error {
+	if d == nil || d.Value == 0 {
+		return fmt.Errorf("%w: %s needs a positive duration", ErrInvalidWindow, name)
+	}
+	if _, valid := durationMap[d.Unit]; !valid {
+		return fmt.Errorf("%w: %s unit %q not valid", ErrInvalidWindow, name, d.Unit)
+	}
+	if !natural && (d.Unit == Month || d.Unit == Year) {
+		return fmt.Errorf("%w: %s cannot use natural month or year %s", ErrInvalidWindow, name, d)
+	}
+	return nil
+}
@@ -1,19 +1,27 @@
 package window
 
 import (
-	"gorm.io/gorm/clause"
+	"fmt"
 	"strconv"
+
+	"github.com/taosdata/tdengine_gorm/clause/internal/statement"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
 	SESSION = iota + 1
 	STATE
 	INTERVAL
+	EVENT
+	COUNT
 )
 
 //[SESSION(ts_col, tol_val)]
-//[STATE_WINDOW(col)]
+//[STATE_WINDOW(col) [TRUE_FOR(duration)]]
 //[INTERVAL(interval_val [, interval_offset]) [SLIDING sliding_val]]
+//[EVENT_WINDOW START WITH start_condition END WITH end_condition [TRUE_FOR(duration)]]
+//[COUNT_WINDOW(count_val [, sliding_val])]
 
 type Window struct {
 	windowType  int
@@ -22,6 +30,12 @@ type Window struct {
 	duration    *Duration
 	offset      *Duration
 	sliding     *Duration
+	trueFor     *Duration
+	start       clause.Expression
+	end         clause.Expression
+	count       uint64
+	countSlide  uint64
+	err         error
 }
 
 //SetSessionWindow create a session window [SESSION(ts_col, tol_val)]
@@ -39,10 +53,43 @@ func SetInterval(duration Duration) Window {
 	return Window{windowType: INTERVAL, duration: &duration}
 }
 
+//SetEventWindow create an event window [EVENT_WINDOW START WITH start_condition END WITH end_condition],
+//the conditions are gorm expressions such as gorm.Expr("voltage > ?", 220)
+func SetEventWindow(start, end clause.Expression) Window {
+	return Window{windowType: EVENT, start: start, end: end}
+}
+
+//SetCountWindow create a count window [COUNT_WINDOW(count_val)]
+func SetCountWindow(count uint64) Window {
+	return Window{windowType: COUNT, count: count}
+}
+
+//SetCountSliding set sliding rows to count window [COUNT_WINDOW(count_val, sliding_val)]
+func (sc Window) SetCountSliding(sliding uint64) Window {
+	if sc.windowType == COUNT {
+		sc.countSlide = sliding
+	} else if sc.err == nil {
+		sc.err = fmt.Errorf("%w: count sliding is only allowed on a COUNT_WINDOW", ErrInvalidWindow)
+	}
+	return sc
+}
+
+//SetTrueFor set the minimum duration of event and state windows [TRUE_FOR(duration)]
+func (sc Window) SetTrueFor(duration Duration) Window {
+	if sc.windowType == EVENT || sc.windowType == STATE {
+		sc.trueFor = &duration
+	} else if sc.err == nil {
+		sc.err = fmt.Errorf("%w: TRUE_FOR is only allowed on EVENT_WINDOW and STATE_WINDOW", ErrInvalidWindow)
+	}
+	return sc
+}
+
 //SetOffset set offset to interval window
 func (sc Window) SetOffset(offset Duration) Window {
 	if sc.windowType == INTERVAL {
 		sc.offset = &offset
+	} else if sc.err == nil {
+		sc.err = fmt.Errorf("%w: OFFSET is only allowed on an INTERVAL window", ErrInvalidWindow)
 	}
 	return sc
 }
@@ -51,39 +98,70 @@ func (sc Window) SetOffset(offset Duration) Window {
 func (sc Window) SetSliding(sliding Duration) Window {
 	if sc.windowType == INTERVAL {
 		sc.sliding = &sliding
+	} else if sc.err == nil {
+		sc.err = fmt.Errorf("%w: SLIDING is only allowed on an INTERVAL window", ErrInvalidWindow)
 	}
 	return sc
 }
 
+// ModifyStatement adds the window to stmt, a window that fails Validate is reported with AddError of stmt
+func (sc Window) ModifyStatement(stmt *gorm.Statement) {
+	statement.AddClause(stmt, sc, sc.Validate())
+}
+
+// Build the window
 func (sc Window) Build(builder clause.Builder) {
 	switch sc.windowType {
 	case SESSION:
 		builder.WriteString("SESSION(")
-		builder.WriteString(sc.tsColumn)
+		builder.WriteQuoted(sc.tsColumn)
 		builder.WriteByte(',')
-		builder.WriteString(strconv.FormatUint(sc.duration.Value, 10))
-		builder.WriteString(string(sc.duration.Unit))
+		builder.WriteString(sc.duration.String())
 		builder.WriteByte(')')
 	case STATE:
 		builder.WriteString("STATE_WINDOW(")
-		builder.WriteString(sc.stateColumn)
+		builder.WriteQuoted(sc.stateColumn)
 		builder.WriteByte(')')
+		sc.buildTrueFor(builder)
 	case INTERVAL:
 		builder.WriteString("INTERVAL(")
-		builder.WriteString(strconv.FormatUint(sc.duration.Value, 10))
-		builder.WriteString(string(sc.duration.Unit))
+		builder.WriteString(sc.duration.String())
 		if sc.offset != nil {
 			builder.WriteByte(',')
-			builder.WriteString(strconv.FormatUint(sc.offset.Value, 10))
-			builder.WriteString(string(sc.offset.Unit))
+			builder.WriteString(sc.offset.String())
 		}
 		builder.WriteByte(')')
 		if sc.sliding != nil {
 			builder.WriteString(" SLIDING(")
-			builder.WriteString(strconv.FormatUint(sc.sliding.Value, 10))
-			builder.WriteString(string(sc.sliding.Unit))
+			builder.WriteString(sc.sliding.String())
 			builder.WriteByte(')')
 		}
+	case EVENT:
+		builder.WriteString("EVENT_WINDOW START WITH ")
+		if sc.start != nil {
+			sc.start.Build(builder)
+		}
+		builder.WriteString(" END WITH ")
+		if sc.end != nil {
+			sc.end.Build(builder)
+		}
+		sc.buildTrueFor(builder)
+	case COUNT:
+		builder.WriteString("COUNT_WINDOW(")
+		builder.WriteString(strconv.FormatUint(sc.count, 10))
+		if sc.countSlide != 0 {
+			builder.WriteByte(',')
+			builder.WriteString(strconv.FormatUint(sc.countSlide, 10))
+		}
+		builder.WriteByte(')')
+	}
+}
+
+func (sc Window) buildTrueFor(builder clause.Builder) {
+	if sc.trueFor != nil {
+		builder.WriteString(" TRUE_FOR(")
+		builder.WriteString(sc.trueFor.String())
+		builder.WriteByte(')')
 	}
 }
 
@@ -1,12 +1,14 @@
 package window_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/taosdata/tdengine_gorm/clause/tests"
 	"github.com/taosdata/tdengine_gorm/clause/window"
 	"testing"
 	"time"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -147,6 +149,84 @@ func TestSetSliding(t *testing.T) {
 	}
 }
 
+func TestSetEventWindow(t *testing.T) {
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "count(*)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "t_1"}}},
+					window.SetEventWindow(clause.Expr{SQL: "voltage > ?", Vars: []interface{}{220}}, clause.Lt{Column: "voltage", Value: 200}),
+				},
+				Result: []string{"SELECT count(*) FROM t_1 EVENT_WINDOW START WITH voltage > ? END WITH voltage < ?"},
+				Vars:   [][][]interface{}{{{220, 200}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "count(*)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "t_1"}}},
+					window.SetEventWindow(clause.Expr{SQL: "status = ?", Vars: []interface{}{"alarm"}}, clause.Expr{SQL: "status <> ?", Vars: []interface{}{"alarm"}}).
+						SetTrueFor(window.Duration{Value: 10, Unit: window.Second}),
+				},
+				Result: []string{"SELECT count(*) FROM t_1 EVENT_WINDOW START WITH status = ? END WITH status <> ? TRUE_FOR(10s)"},
+				Vars:   [][][]interface{}{{{"alarm", "alarm"}}},
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "count(*)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "t_1"}}},
+					window.SetStateWindow("status").SetTrueFor(window.Duration{Value: 1, Unit: window.Minute}),
+				},
+				Result: []string{"SELECT count(*) FROM t_1 STATE_WINDOW(status) TRUE_FOR(1m)"},
+				Vars:   nil,
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestSetCountWindow(t *testing.T) {
+	var (
+		results = []struct {
+			Clauses []clause.Interface
+			Result  []string
+			Vars    [][][]interface{}
+		}{
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(value)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "t_1"}}},
+					window.SetCountWindow(10),
+				},
+				Result: []string{"SELECT avg(value) FROM t_1 COUNT_WINDOW(10)"},
+				Vars:   nil,
+			},
+			{
+				Clauses: []clause.Interface{
+					clause.Select{Columns: []clause.Column{{Name: "avg(value)", Raw: true}}},
+					clause.From{Tables: []clause.Table{{Name: "t_1"}}},
+					window.SetCountWindow(10).SetCountSliding(5),
+				},
+				Result: []string{"SELECT avg(value) FROM t_1 COUNT_WINDOW(10,5)"},
+				Vars:   nil,
+			},
+		}
+	)
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			tests.CheckBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
 func TestNewDurationFromTimeDuration(t *testing.T) {
 	duration5Min, err := window.NewDurationFromTimeDuration(time.Minute * 5)
 	if err != nil {
@@ -170,7 +250,7 @@ func TestNewDurationFromTimeDuration(t *testing.T) {
 					clause.From{Tables: []clause.Table{{Name: "t_1"}}},
 					window.SetInterval(*duration5Min),
 				},
-				Result: []string{"SELECT t_1.avg(value) FROM t_1 INTERVAL(300000000u)"},
+				Result: []string{"SELECT t_1.avg(value) FROM t_1 INTERVAL(5m)"},
 				Vars:   nil,
 			},
 		}
@@ -227,3 +307,113 @@ func TestParseDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestDurationBestFit(t *testing.T) {
+	for duration, expect := range map[time.Duration]string{
+		time.Minute * 5:         "5m",
+		time.Second * 90:        "90s",
+		time.Hour * 24 * 14:     "2w",
+		time.Hour * 48:          "2d",
+		time.Millisecond * 1500: "1500a",
+		time.Microsecond * 3:    "3u",
+		time.Nanosecond * 1500:  "1500b",
+	} {
+		d, err := window.NewNanosecondDurationFromTimeDuration(duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u, err := window.NewDurationFromTimeDuration(duration); d.Unit == window.Nanosecond {
+			if !errors.Is(err, window.ErrNanosecondDuration) {
+				t.Errorf("%s: expect ErrNanosecondDuration got %v %v", duration, u, err)
+			}
+		} else if err != nil || *u != *d {
+			t.Errorf("%s: expect %s got %v %v", duration, d, u, err)
+		}
+		if d.String() != expect {
+			t.Errorf("%s: expect %s got %s", duration, expect, d)
+		}
+		back, ok := d.TimeDuration()
+		if !ok || back != duration {
+			t.Errorf("%s: converted back to %s %v", d, back, ok)
+		}
+	}
+	nanoseconds, err := window.ParseDuration("10b")
+	if err != nil || *nanoseconds != (window.Duration{Value: 10, Unit: window.Nanosecond}) {
+		t.Errorf("expect 10b got %v %v", nanoseconds, err)
+	}
+	if _, ok := (window.Duration{Value: 1, Unit: window.Month}).TimeDuration(); ok {
+		t.Error("expect natural month not to convert")
+	}
+	if _, ok := (window.Duration{Value: 1 << 62, Unit: window.Week}).TimeDuration(); ok {
+		t.Error("expect overflow not to convert")
+	}
+}
+
+func TestDurationCompare(t *testing.T) {
+	minute := window.Duration{Value: 1, Unit: window.Minute}
+	if !minute.Equal(window.Duration{Value: 60, Unit: window.Second}) {
+		t.Error("expect 1m equals 60s")
+	}
+	if !minute.Less(window.Duration{Value: 61, Unit: window.Second}) || minute.Less(minute) {
+		t.Error("expect 1m less than 61s only")
+	}
+	if c, err := (window.Duration{Value: 1, Unit: window.Year}).Compare(window.Duration{Value: 12, Unit: window.Month}); err != nil || c != 0 {
+		t.Errorf("expect 1y equals 12n got %d %v", c, err)
+	}
+	if _, err := minute.Compare(window.Duration{Value: 1, Unit: window.Month}); err != window.ErrIncomparable {
+		t.Errorf("expect ErrIncomparable got %v", err)
+	}
+}
+
+func TestWindowValidate(t *testing.T) {
+	minutes := func(v uint64) window.Duration { return window.Duration{Value: v, Unit: window.Minute} }
+	month := window.Duration{Value: 1, Unit: window.Month}
+	invalid := []window.Window{
+		window.SetInterval(minutes(10)).SetSliding(minutes(11)),
+		window.SetInterval(minutes(10)).SetOffset(minutes(10)),
+		window.SetInterval(minutes(0)),
+		window.SetInterval(window.Duration{Value: 1, Unit: "x"}),
+		window.SetInterval(month).SetOffset(month),
+		window.SetInterval(month).SetSliding(minutes(1)),
+		window.SetSessionWindow("ts", month),
+		window.SetSessionWindow("", minutes(1)),
+		window.SetStateWindow("status").SetSliding(minutes(1)),
+		window.SetSessionWindow("ts", minutes(1)).SetOffset(minutes(1)),
+		window.SetEventWindow(clause.Expr{SQL: "voltage > 220"}, nil),
+		window.SetEventWindow(clause.Expr{SQL: "voltage > 220"}, clause.Expr{SQL: "voltage < 200"}).SetTrueFor(month),
+		window.SetInterval(minutes(1)).SetTrueFor(minutes(1)),
+		window.SetCountWindow(0),
+		window.SetCountWindow(5).SetCountSliding(6),
+		window.SetInterval(minutes(1)).SetCountSliding(1),
+	}
+	for _, w := range invalid {
+		if err := w.Validate(); !errors.Is(err, window.ErrInvalidWindow) {
+			t.Errorf("%+v: expect ErrInvalidWindow got %v", w, err)
+		}
+	}
+	valid := []window.Window{
+		window.SetInterval(minutes(10)).SetOffset(minutes(5)).SetSliding(minutes(10)),
+		window.SetInterval(month).SetSliding(month),
+		window.SetInterval(window.Duration{Value: 1, Unit: window.Year}).SetOffset(minutes(1)).SetSliding(month),
+		window.SetStateWindow("status"),
+		window.SetCountWindow(5).SetCountSliding(5),
+	}
+	for _, w := range valid {
+		if err := w.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", w, err)
+		}
+	}
+
+	db, err := gorm.Open(tests.DummyDialector{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := &gorm.Statement{DB: db.Session(&gorm.Session{}), Clauses: map[string]clause.Clause{}}
+	stmt.AddClause(window.SetInterval(minutes(10)).SetSliding(minutes(11)))
+	if !errors.Is(stmt.Error, window.ErrInvalidWindow) {
+		t.Errorf("expect ErrInvalidWindow on the statement got %v", stmt.Error)
+	}
+	if _, ok := stmt.Clauses["WINDOW"]; !ok {
+		t.Errorf("expect the WINDOW clause to be added")
+	}
+}
@@ -0,0 +1,318 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"github.com/taosdata/tdengine_gorm/clause/insert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultMaxSQLLength is the default maxSQLLength of TDengine 2.x.
+const DefaultMaxSQLLength = 65480
+
+// ErrSQLTooLong is returned by Create when a single row does not fit in maxSQLLength.
+var ErrSQLTooLong = errors.New("sql statement exceeds max sql length")
+
+// ErrEmptyMultiTable is returned by CreateMultiTable for a multi table insert without tables or with a table without rows.
+var ErrEmptyMultiTable = errors.New("multi table insert without rows")
+
+// createCallback replaces the gorm create callback, rows are split into several INSERT statements
+// so that none of them is longer than maxSQLLength bytes.
+// Statements with CREATE TABLE are executed as they are built, a multi table insert is split by table rows.
+// Other rows go through stmtConn instead when it is not nil.
+// Values that implement SubTableModel are inserted into their subtables with USING, one subtable after another.
+// Tag fields are left out of the inserted columns and ON CONFLICT is checked against the update mode of the database.
+func createCallback(maxSQLLength int, stmtConn StmtConn, update database.UpdateMode) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil {
+			return
+		}
+		stmt := db.Statement
+		if stmt.Schema != nil && !stmt.Unscoped {
+			for _, c := range stmt.Schema.CreateClauses {
+				stmt.AddClause(c)
+			}
+		}
+		if stmt.SQL.Len() > 0 {
+			execCreate(db)
+			return
+		}
+		if multiTable, ok := stmt.Clauses["INSERT"].Expression.(insert.MultiTable); ok {
+			createMultiTable(db, multiTable, maxSQLLength)
+			return
+		}
+		stmt.AddClauseIfNotExists(clause.Insert{})
+		values := withoutTags(stmt, callbacks.ConvertToCreateValues(stmt))
+		if db.Error != nil {
+			return
+		}
+		if err := checkOnConflict(stmt, values, update); err != nil {
+			db.AddError(err)
+			return
+		}
+		createRows(db, values, maxSQLLength, stmtConn)
+	}
+}
+
+// CreateMultiTable inserts the rows of several tables in one statement, or in several statements when they do not fit
+// in maxSQLLength, see insert.NewMultiTable.
+func CreateMultiTable(db *gorm.DB, tables ...*insert.Table) *gorm.DB {
+	tx := db.Clauses(insert.NewMultiTable(tables...))
+	return tx.Callback().Create().Execute(tx)
+}
+
+// createMultiTable executes a multi table insert, the rows are split into several statements
+// so that none of them is longer than maxSQLLength bytes.
+func createMultiTable(db *gorm.DB, multiTable insert.MultiTable, maxSQLLength int) {
+	stmt := db.Statement
+	if len(multiTable.Tables()) == 0 {
+		db.AddError(fmt.Errorf("%w: no tables", ErrEmptyMultiTable))
+		return
+	}
+	for _, table := range multiTable.Tables() {
+		if len(table.Values) == 0 {
+			db.AddError(fmt.Errorf("%w: %s has no rows", ErrEmptyMultiTable, table.Table))
+			return
+		}
+	}
+	chunks, err := splitMultiTable(stmt, multiTable, maxSQLLength)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	if len(chunks) == 1 || db.DryRun {
+		stmt.Build(stmt.BuildClauses...)
+		execCreate(db)
+		return
+	}
+	var rowsAffected int64
+	for i, chunk := range chunks {
+		stmt.SQL.Reset()
+		stmt.Vars = nil
+		stmt.AddClause(chunk)
+		stmt.Build(stmt.BuildClauses...)
+		if err := execChunk(db, i == len(chunks)-1); err != nil {
+			db.AddError(fmt.Errorf("insert chunk %d/%d: %w", i+1, len(chunks), err))
+			break
+		}
+		rowsAffected += db.RowsAffected
+	}
+	db.RowsAffected = rowsAffected
+}
+
+// createRows inserts the rows into the subtables of SubTableModel values, or into the table of the statement.
+func createRows(db *gorm.DB, values clause.Values, maxSQLLength int, stmtConn StmtConn) {
+	stmt := db.Statement
+	_, containsCreateTable := stmt.Clauses["CREATE TABLE"]
+	_, containsUsing := stmt.Clauses["USING"]
+	if !containsCreateTable && !containsUsing {
+		groups, err := subTableGroups(stmt, values)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		if groups != nil {
+			var rowsAffected int64
+			table := stmt.Table
+			for _, group := range groups {
+				stmt.Table = group.table
+				stmt.AddClause(group.using)
+				stmt.SQL.Reset()
+				stmt.Vars = nil
+				insertValues(db, group.values, maxSQLLength, stmtConn)
+				if db.Error != nil {
+					break
+				}
+				rowsAffected += db.RowsAffected
+			}
+			stmt.Table = table
+			delete(stmt.Clauses, "USING")
+			db.RowsAffected = rowsAffected
+			return
+		}
+	}
+	insertValues(db, values, maxSQLLength, stmtConn)
+}
+
+// insertValues inserts the rows into the table of the statement.
+func insertValues(db *gorm.DB, values clause.Values, maxSQLLength int, stmtConn StmtConn) {
+	stmt := db.Statement
+	_, containsCreateTable := stmt.Clauses["CREATE TABLE"]
+	if containsCreateTable || (stmtConn == nil && (len(values.Values) <= 1 || db.DryRun)) {
+		stmt.AddClause(values)
+		stmt.Build(stmt.BuildClauses...)
+		execCreate(db)
+		return
+	}
+	if stmtConn != nil {
+		stmtCreate(db, stmtConn, values)
+		return
+	}
+	chunks, err := splitValues(stmt, values, maxSQLLength)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	var rowsAffected int64
+	first := 0
+	for i, chunk := range chunks {
+		stmt.SQL.Reset()
+		stmt.Vars = nil
+		stmt.AddClause(clause.Values{Columns: values.Columns, Values: chunk})
+		stmt.Build(stmt.BuildClauses...)
+		if err := execChunk(db, i == len(chunks)-1); err != nil {
+			db.AddError(fmt.Errorf("insert chunk %d/%d (rows %d-%d): %w", i+1, len(chunks), first+1, first+len(chunk), err))
+			break
+		}
+		rowsAffected += db.RowsAffected
+		first += len(chunk)
+	}
+	db.RowsAffected = rowsAffected
+}
+
+// execChunk executes the statement of one of several chunks. gorm logs the statement left in stmt.SQL
+// with the error of the callback, which is the last chunk or the chunk that failed,
+// so only the chunks that succeed before it are logged here.
+func execChunk(db *gorm.DB, last bool) error {
+	stmt := db.Statement
+	begin := time.Now()
+	result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	db.RowsAffected = rowsAffected
+	if !last {
+		sql, vars := stmt.SQL.String(), stmt.Vars
+		db.Logger.Trace(stmt.Context, begin, func() (string, int64) {
+			return db.Dialector.Explain(sql, vars...), rowsAffected
+		}, nil)
+	}
+	return nil
+}
+
+func execCreate(db *gorm.DB) {
+	if db.DryRun || db.Error != nil {
+		return
+	}
+	result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.RowsAffected, _ = result.RowsAffected()
+}
+
+// splitValues groups the rows so that the statement of each group is at most maxSQLLength bytes.
+// Values are written as literals by BindVarTo, so the built statement is what the server receives.
+func splitValues(stmt *gorm.Statement, values clause.Values, maxSQLLength int) ([][][]interface{}, error) {
+	rowLengths := make([]int, len(values.Values))
+	for i, row := range values.Values {
+		rowStmt := &gorm.Statement{DB: stmt.DB}
+		rowStmt.AddVar(rowStmt, row)
+		if rowStmt.Error != nil {
+			return nil, rowStmt.Error
+		}
+		rowLengths[i] = rowStmt.SQL.Len()
+	}
+	stmt.AddClause(clause.Values{Columns: values.Columns, Values: values.Values[:1]})
+	stmt.Build(stmt.BuildClauses...)
+	header := stmt.SQL.Len() - rowLengths[0]
+	stmt.SQL.Reset()
+	stmt.Vars = nil
+
+	var (
+		chunks [][][]interface{}
+		start  = 0
+		size   = header
+	)
+	for i, rowLength := range rowLengths {
+		if header+rowLength > maxSQLLength {
+			return nil, fmt.Errorf("%w: row %d needs %d bytes, max sql length is %d", ErrSQLTooLong, i+1, header+rowLength, maxSQLLength)
+		}
+		if i > start && size+1+rowLength > maxSQLLength {
+			chunks = append(chunks, values.Values[start:i])
+			start, size = i, header
+		}
+		if i > start {
+			size++
+		}
+		size += rowLength
+	}
+	return append(chunks, values.Values[start:]), nil
+}
+
+// splitMultiTable groups the rows of a multi table insert so that the statement of each group is at most maxSQLLength bytes,
+// the rows of a table are continued in the next statement with the table written again.
+func splitMultiTable(stmt *gorm.Statement, multiTable insert.MultiTable, maxSQLLength int) ([]insert.MultiTable, error) {
+	prefix := len("INSERT INTO")
+	var (
+		chunks []insert.MultiTable
+		chunk  insert.MultiTable
+		size   = prefix
+	)
+	for _, table := range multiTable.Tables() {
+		// the space before the table and "tb [USING ...] (columns) VALUES "
+		headerStmt := &gorm.Statement{DB: stmt.DB}
+		(&insert.Table{Table: table.Table, Using: table.Using, Columns: table.Columns}).Build(headerStmt)
+		if headerStmt.Error != nil {
+			return nil, headerStmt.Error
+		}
+		header := 1 + headerStmt.SQL.Len()
+		var part *insert.Table
+		for i, row := range table.Values {
+			rowStmt := &gorm.Statement{DB: stmt.DB}
+			rowStmt.AddVar(rowStmt, row)
+			if rowStmt.Error != nil {
+				return nil, rowStmt.Error
+			}
+			rowLength := rowStmt.SQL.Len()
+			if prefix+header+rowLength > maxSQLLength {
+				return nil, fmt.Errorf("%w: row %d of %s needs %d bytes, max sql length is %d", ErrSQLTooLong, i+1, table.Table, prefix+header+rowLength, maxSQLLength)
+			}
+			length := 1 + rowLength
+			if part == nil {
+				length = header + rowLength
+			}
+			if size+length > maxSQLLength {
+				chunks = append(chunks, chunk)
+				chunk, part, size = insert.NewMultiTable(), nil, prefix
+				length = header + rowLength
+			}
+			if part == nil {
+				part = &insert.Table{Table: table.Table, Using: table.Using, Columns: table.Columns}
+				chunk = chunk.AddTables(part)
+			}
+			part.Values = append(part.Values, row)
+			size += length
+		}
+	}
+	return append(chunks, chunk), nil
+}
+
+// detectMaxSQLLength reads maxSQLLength from SHOW VARIABLES.
+func detectMaxSQLLength(db *gorm.DB) (int, error) {
+	rows, err := db.Raw("SHOW VARIABLES").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return 0, err
+		}
+		if name == "maxSQLLength" {
+			return showInt(value), nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("maxSQLLength not found in SHOW VARIABLES")
+}
@@ -0,0 +1,165 @@
+package tdengine_gorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/insert"
+	"github.com/taosdata/tdengine_gorm/clause/using"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func multiTables(ts time.Time) []*insert.Table {
+	return []*insert.Table{
+		insert.NewTableUsing("d1001", "meters", []using.TagPair{{Name: "location", Value: "SF"}}, []string{"ts", "current"}, [][]interface{}{
+			{ts, 10.2},
+			{ts.Add(time.Second), 10.3},
+		}),
+		insert.NewTable("d1002", []string{"ts", "current"}, [][]interface{}{
+			{ts, 11.5},
+		}),
+	}
+}
+
+func TestMultiTableInsert(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	result := CreateMultiTable(db, multiTables(ts)...)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if err := db.Table("meters").Clauses(insert.NewMultiTable(multiTables(ts)...)).Create(map[string]interface{}{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2),('2021-08-11T09:43:01Z',10.3) d1002 (ts,current) VALUES ('2021-08-11T09:43:00Z',11.5)",
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2),('2021-08-11T09:43:01Z',10.3) d1002 (ts,current) VALUES ('2021-08-11T09:43:00Z',11.5)",
+	)
+
+	for _, tables := range [][]*insert.Table{
+		nil,
+		{insert.NewTable("d1001", []string{"ts", "current"}, [][]interface{}{{ts, 10.2}}), insert.NewTable("d1002", []string{"ts", "current"}, nil)},
+	} {
+		if err := CreateMultiTable(db, tables...).Error; !errors.Is(err, ErrEmptyMultiTable) {
+			t.Errorf("expect ErrEmptyMultiTable got %v", err)
+		}
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2),('2021-08-11T09:43:01Z',10.3) d1002 (ts,current) VALUES ('2021-08-11T09:43:00Z',11.5)",
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2),('2021-08-11T09:43:01Z',10.3) d1002 (ts,current) VALUES ('2021-08-11T09:43:00Z',11.5)",
+	)
+}
+
+func TestMultiTableSplit(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	// the first row of d1001 is 101 bytes with INSERT INTO, the second row 30 bytes and d1002 56 bytes
+	db, d := openRecordDB(t, Dialect{MaxSQLLength: 120})
+	result := CreateMultiTable(db, multiTables(ts)...)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.RowsAffected != 3 {
+		t.Errorf("expect 3 rows affected got %d", result.RowsAffected)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2)",
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:01Z',10.3)",
+		"INSERT INTO d1002 (ts,current) VALUES ('2021-08-11T09:43:00Z',11.5)",
+	)
+
+	db, d = openRecordDB(t, Dialect{MaxSQLLength: 101 + 30})
+	if err := CreateMultiTable(db, multiTables(ts)...).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2),('2021-08-11T09:43:01Z',10.3)",
+		"INSERT INTO d1002 (ts,current) VALUES ('2021-08-11T09:43:00Z',11.5)",
+	)
+
+	db, d = openRecordDB(t, Dialect{MaxSQLLength: 100})
+	if err := CreateMultiTable(db, multiTables(ts)...).Error; !errors.Is(err, ErrSQLTooLong) {
+		t.Errorf("expect ErrSQLTooLong got %v", err)
+	}
+	d.AssertExecs(t)
+}
+
+type reading struct {
+	TS    time.Time
+	Value int
+}
+
+func (reading) TableName() string {
+	return "d1001"
+}
+
+func TestCreateSplit(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	readings := make([]reading, 5)
+	for i := range readings {
+		readings[i] = reading{TS: ts.Add(time.Duration(i) * time.Second), Value: i}
+	}
+	// INSERT INTO d1001 (ts,value) VALUES is 36 bytes, each row 26 bytes and a comma
+	db, d := openRecordDB(t, Dialect{MaxSQLLength: 36 + 26*2 + 1})
+	result := db.Create(&readings)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.RowsAffected != 5 {
+		t.Errorf("expect 5 rows affected got %d", result.RowsAffected)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:00Z',0),('2021-08-11T09:43:01Z',1)",
+		"INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:02Z',2),('2021-08-11T09:43:03Z',3)",
+		"INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:04Z',4)",
+	)
+
+	db, d = openRecordDB(t, Dialect{MaxSQLLength: 36 + 26*2 + 1})
+	failed := "INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:02Z',2),('2021-08-11T09:43:03Z',3)"
+	d.Error(failed, errors.New("disk full"))
+	traces := &traceLogger{Interface: logger.Discard}
+	err := db.Session(&gorm.Session{Logger: traces}).Create(&readings).Error
+	if err == nil || err.Error() != "insert chunk 2/3 (rows 3-4): disk full" {
+		t.Errorf("unexpected error %v", err)
+	}
+	// the first chunk is logged by execChunk, the failed chunk once by gorm with the error
+	expect := []string{
+		"INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:00Z',0),('2021-08-11T09:43:01Z',1) <nil>",
+		failed + " insert chunk 2/3 (rows 3-4): disk full",
+	}
+	if !reflect.DeepEqual(traces.traces, expect) {
+		t.Errorf("expect traces %q got %q", expect, traces.traces)
+	}
+
+	db, _ = openRecordDB(t, Dialect{MaxSQLLength: 36 + 20})
+	if err = db.Create(&readings).Error; !errors.Is(err, ErrSQLTooLong) {
+		t.Errorf("expect ErrSQLTooLong got %v", err)
+	}
+}
+
+func TestDetectMaxSQLLength(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SHOW VARIABLES", []string{"name", "value"},
+		[]driver.Value{"maxRows", "4096"},
+		[]driver.Value{"maxSQLLength", "1048576"},
+	)
+	if length, err := detectMaxSQLLength(db); err != nil || length != 1048576 {
+		t.Errorf("expect 1048576 got %d %v", length, err)
+	}
+}
+
+// traceLogger records the traced statements with their errors.
+type traceLogger struct {
+	logger.Interface
+	traces []string
+}
+
+func (l *traceLogger) Trace(_ context.Context, _ time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	l.traces = append(l.traces, fmt.Sprintf("%s %v", sql, err))
+}
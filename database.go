@@ -0,0 +1,170 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDatabaseNotExist is returned by DescribeDatabase when SHOW DATABASES does not list the database.
+	ErrDatabaseNotExist = errors.New("database does not exist")
+	// ErrAlterDatabase is returned by AlterDatabase for options that are fixed once the database is created.
+	ErrAlterDatabase = errors.New("DAYS, DURATION and PRECISION cannot be altered")
+)
+
+// Database is a database listed by SHOW DATABASES.
+type Database struct {
+	Name    string
+	Options database.DatabaseOptions
+}
+
+// CreateDatabase creates the database, with ifNotExists an existing database is not an error.
+func (m Migrator) CreateDatabase(name string, ifNotExists bool, options database.DatabaseOptions) error {
+	return m.execClause(database.NewCreate(name, ifNotExists, options))
+}
+
+// AlterDatabase alters KEEP, UPDATE, REPLICA, BLOCKS and CACHELAST of the database.
+func (m Migrator) AlterDatabase(name string, options database.DatabaseOptions) error {
+	if options.Days != 0 || options.Duration != 0 || options.Precision != "" {
+		return fmt.Errorf("%w: %s", ErrAlterDatabase, name)
+	}
+	if options == (database.DatabaseOptions{}) {
+		return fmt.Errorf("no option to alter for database %s", name)
+	}
+	return m.execClause(database.NewAlter(name, options))
+}
+
+// DropDatabase drops the database, with ifExists a missing database is not an error.
+func (m Migrator) DropDatabase(name string, ifExists bool) error {
+	return m.execClause(database.NewDrop(name, ifExists))
+}
+
+// Databases lists the databases and their options with SHOW DATABASES.
+func (m Migrator) Databases() ([]Database, error) {
+	rows, err := m.DB.Raw("SHOW DATABASES").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var databases []Database
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range dest {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		var db Database
+		for i, column := range columns {
+			value := values[i]
+			switch column = strings.ToLower(column); {
+			case column == "name":
+				db.Name = showString(value)
+			case strings.HasPrefix(column, "keep"):
+				db.Options.Keep = showDays(value)
+			case column == "days":
+				db.Options.Days = showDays(value)
+			case column == "duration":
+				db.Options.Duration = showDays(value)
+			case column == "precision":
+				db.Options.Precision = database.Precision(showString(value))
+			case column == "update":
+				db.Options.Update = database.UpdateMode(showInt(value) + 1)
+			case column == "replica":
+				db.Options.Replica = showInt(value)
+			case column == "blocks":
+				db.Options.Blocks = showInt(value)
+			case column == "cachelast":
+				db.Options.CacheLast = database.CacheLastMode(showInt(value) + 1)
+			}
+		}
+		databases = append(databases, db)
+	}
+	return databases, rows.Err()
+}
+
+// DescribeDatabase returns the options of the database.
+func (m Migrator) DescribeDatabase(name string) (*Database, error) {
+	databases, err := m.Databases()
+	if err != nil {
+		return nil, err
+	}
+	for i := range databases {
+		if strings.EqualFold(databases[i].Name, name) {
+			return &databases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrDatabaseNotExist, name)
+}
+
+// execClause builds a statement from a clause that is not part of the gorm callbacks and executes it.
+func (m Migrator) execClause(c clause.Expression) error {
+	stmt := &gorm.Statement{DB: m.DB}
+	c.Build(stmt)
+	return m.DB.Exec(stmt.SQL.String()).Error
+}
+
+func showString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// showInt converts a number of SHOW output, strings like "3650,3650,3650" give their first number.
+func showInt(value interface{}) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case int32:
+		return int(v)
+	case int16:
+		return int(v)
+	case int8:
+		return int(v)
+	case int:
+		return v
+	}
+	s := showString(value)
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	i, _ := strconv.Atoi(s[:end])
+	return i
+}
+
+// showDays converts a number of days of SHOW output, TDengine 3.x writes KEEP and DURATION with a unit
+// like "14400m" or "5256000m,5256000m,5256000m", minutes and hours are converted to whole days.
+func showDays(value interface{}) int {
+	days := showInt(value)
+	unit := strings.TrimLeft(showString(value), "0123456789")
+	if unit == "" {
+		return days
+	}
+	switch unit[0] {
+	case 'm':
+		return days / (24 * 60)
+	case 'h':
+		return days / 24
+	}
+	return days
+}
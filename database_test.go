@@ -0,0 +1,79 @@
+package tdengine_gorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/database"
+)
+
+func TestDatabaseLifecycle(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SHOW DATABASES", []string{"name", "created_time", "ntables", "vgroups", "replica", "quorum", "days", "keep0,keep1,keep(D)", "cache(MB)", "blocks", "minrows", "maxrows", "wallevel", "fsync", "comp", "cachelast", "precision", "update", "status"},
+		[]driver.Value{"log", time.Now(), int64(4), int64(1), int64(1), int64(1), int64(10), "30,30,30", int64(1), int64(3), int64(100), int64(4096), int64(1), int64(3000), int64(2), int64(0), "us", int64(0), "ready"},
+		[]driver.Value{"power", time.Now(), int64(4), int64(1), int64(1), int64(1), int64(10), "3650,3650,3650", int64(16), int64(6), int64(100), int64(4096), int64(1), int64(3000), int64(2), int64(1), "ns", int64(2), "ready"},
+	)
+	migrator := db.Migrator().(Migrator)
+	if err := migrator.CreateDatabase("power", false, database.DatabaseOptions{Keep: 3650, Precision: database.PrecisionNanosecond, Update: database.UpdatePartial}); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.AlterDatabase("power", database.DatabaseOptions{CacheLast: database.CacheLastRow}); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.AlterDatabase("power", database.DatabaseOptions{Precision: database.PrecisionMicrosecond}); !errors.Is(err, ErrAlterDatabase) {
+		t.Errorf("expect ErrAlterDatabase got %v", err)
+	}
+	if err := migrator.DropDatabase("power", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.CreateDatabase("power", true, database.DatabaseOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.DropDatabase("power", true); err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"CREATE DATABASE power KEEP 3650 PRECISION 'ns' UPDATE 2",
+		"ALTER DATABASE power CACHELAST 1",
+		"DROP DATABASE power",
+		"CREATE DATABASE IF NOT EXISTS power",
+		"DROP DATABASE IF EXISTS power",
+	)
+	power, err := migrator.DescribeDatabase("power")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := database.DatabaseOptions{
+		Keep:      3650,
+		Days:      10,
+		Precision: database.PrecisionNanosecond,
+		Update:    database.UpdatePartial,
+		Replica:   1,
+		Blocks:    6,
+		CacheLast: database.CacheLastRow,
+	}
+	if power.Options != expect {
+		t.Errorf("expect %+v got %+v", expect, power.Options)
+	}
+	if _, err = migrator.DescribeDatabase("test"); !errors.Is(err, ErrDatabaseNotExist) {
+		t.Errorf("expect ErrDatabaseNotExist got %v", err)
+	}
+}
+
+func TestDescribeDatabaseDuration(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	// TDengine 3.x writes KEEP and DURATION in minutes
+	d.Result("SHOW DATABASES", []string{"name", "create_time", "vgroups", "ntables", "replica", "strict", "duration", "keep", "buffer", "pagesize", "pages", "minrows", "maxrows", "comp", "precision", "status", "retentions", "single_stable", "cachemodel", "cachesize", "wal_level"},
+		[]driver.Value{"power", time.Now(), int64(2), int64(4), int64(1), "off", "14400m", "5256000m,5256000m,5256000m", int64(96), int64(4), int64(256), int64(100), int64(4096), int64(2), "ms", "ready", nil, false, "none", int64(1), int64(1)},
+	)
+	power, err := db.Migrator().(Migrator).DescribeDatabase("power")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := database.DatabaseOptions{Keep: 3650, Duration: 10, Precision: database.PrecisionMillisecond, Replica: 1}
+	if power.Options != expect {
+		t.Errorf("expect %+v got %+v", expect, power.Options)
+	}
+}
@@ -0,0 +1,345 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrDelete is returned by Delete for conditions that TDengine cannot delete by and for soft delete models.
+var ErrDelete = errors.New("delete not supported")
+
+// deleteCallback replaces the gorm delete callback. TDengine 3.x deletes the rows of a table or supertable in a range
+// of the timestamp primary key, so the conditions are =, <, <=, >, >= and IN on the timestamp
+// and = and IN on tags and tbname, joined by AND. They are clause expressions such as clause.Gte, or SQL conditions
+// of the form "column op ?" joined by AND, other SQL conditions are only sent with AllowGlobalUpdate.
+// Conditions without a timestamp bound delete every row of the selected tables and need AllowGlobalUpdate
+// like a delete without conditions. Values with a timestamp are deleted by ts IN, split into several statements
+// so that none of them is longer than maxSQLLength bytes, and soft delete models are rejected unless Unscoped.
+func deleteCallback(maxSQLLength int) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil {
+			return
+		}
+		stmt := db.Statement
+		if stmt.Schema == nil {
+			db.AddError(fmt.Errorf("%w: Delete needs a model to find the timestamp of", ErrDelete))
+			return
+		}
+		if len(stmt.Schema.DeleteClauses) > 0 && !stmt.Unscoped {
+			db.AddError(fmt.Errorf("%w: %s is soft deleted but TDengine cannot update rows, use Unscoped to delete them", ErrDelete, stmt.Schema.Name))
+			return
+		}
+		ts := timestampField(stmt.Schema)
+		if ts == nil {
+			db.AddError(fmt.Errorf("%w: %s has no timestamp field", ErrDelete, stmt.Schema.Name))
+			return
+		}
+		where, _ := stmt.Clauses["WHERE"].Expression.(clause.Where)
+		bounded, err := checkDeleteConditions(stmt, ts, where.Exprs)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		timestamps := deletedTimestamps(stmt, ts)
+		if !bounded && len(timestamps) == 0 && !db.AllowGlobalUpdate {
+			db.AddError(gorm.ErrMissingWhereClause)
+			return
+		}
+
+		stmt.AddClauseIfNotExists(clause.Delete{})
+		stmt.AddClauseIfNotExists(clause.From{})
+		if len(timestamps) == 0 {
+			stmt.Build(stmt.BuildClauses...)
+			execCreate(db)
+			return
+		}
+		chunks, err := splitTimestamps(stmt, where, ts, timestamps, maxSQLLength)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		var rowsAffected int64
+		for i, chunk := range chunks {
+			stmt.SQL.Reset()
+			stmt.Vars = nil
+			whereTimestamps(stmt, where, ts, chunk)
+			stmt.Build(stmt.BuildClauses...)
+			if len(chunks) == 1 {
+				execCreate(db)
+				return
+			}
+			if db.DryRun {
+				continue
+			}
+			if err := execChunk(db, i == len(chunks)-1); err != nil {
+				db.AddError(fmt.Errorf("delete chunk %d/%d: %w", i+1, len(chunks), err))
+				break
+			}
+			rowsAffected += db.RowsAffected
+		}
+		db.RowsAffected = rowsAffected
+	}
+}
+
+// whereTimestamps replaces the WHERE clause of stmt by the conditions of where and ts IN timestamps.
+func whereTimestamps(stmt *gorm.Statement, where clause.Where, ts *schema.Field, timestamps []interface{}) {
+	column := clause.Column{Table: clause.CurrentTable, Name: ts.DBName}
+	exprs := append(where.Exprs[:len(where.Exprs):len(where.Exprs)], clause.IN{Column: column, Values: timestamps})
+	delete(stmt.Clauses, "WHERE")
+	stmt.AddClause(clause.Where{Exprs: exprs})
+}
+
+// splitTimestamps groups the timestamps so that the statement of each group is at most maxSQLLength bytes.
+func splitTimestamps(stmt *gorm.Statement, where clause.Where, ts *schema.Field, timestamps []interface{}, maxSQLLength int) ([][]interface{}, error) {
+	whereTimestamps(stmt, where, ts, timestamps)
+	stmt.Build(stmt.BuildClauses...)
+	size := stmt.SQL.Len()
+	stmt.SQL.Reset()
+	stmt.Vars = nil
+	if size <= maxSQLLength {
+		return [][]interface{}{timestamps}, nil
+	}
+	lengths := make([]int, len(timestamps))
+	header := size - (len(timestamps) - 1)
+	for i, value := range timestamps {
+		valueStmt := &gorm.Statement{DB: stmt.DB}
+		valueStmt.AddVar(valueStmt, value)
+		if valueStmt.Error != nil {
+			return nil, valueStmt.Error
+		}
+		lengths[i] = valueStmt.SQL.Len()
+		header -= lengths[i]
+	}
+	var (
+		chunks [][]interface{}
+		start  = 0
+	)
+	size = header
+	for i, length := range lengths {
+		if header+length > maxSQLLength {
+			return nil, fmt.Errorf("%w: timestamp %d needs %d bytes, max sql length is %d", ErrSQLTooLong, i+1, header+length, maxSQLLength)
+		}
+		if i > start && size+1+length > maxSQLLength {
+			chunks = append(chunks, timestamps[start:i])
+			start, size = i, header
+		}
+		if i > start {
+			size++
+		}
+		size += length
+	}
+	return append(chunks, timestamps[start:]), nil
+}
+
+// deletedTimestamps are the non zero timestamps of the deleted values.
+func deletedTimestamps(stmt *gorm.Statement, ts *schema.Field) []interface{} {
+	var values []interface{}
+	add := func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		if rv.Kind() != reflect.Struct || rv.Type() != stmt.Schema.ModelType {
+			return
+		}
+		if value, isZero := ts.ValueOf(rv); !isZero {
+			values = append(values, value)
+		}
+	}
+	switch rv := stmt.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			add(rv.Index(i))
+		}
+	default:
+		add(rv)
+	}
+	return values
+}
+
+// checkDeleteConditions checks the conditions of a delete, bounded is true when one of them bounds the timestamp.
+func checkDeleteConditions(stmt *gorm.Statement, ts *schema.Field, exprs []clause.Expression) (bool, error) {
+	bounded := false
+	for _, expr := range exprs {
+		b, err := checkDeleteCondition(stmt, ts, expr)
+		if err != nil {
+			return false, err
+		}
+		bounded = bounded || b
+	}
+	return bounded, nil
+}
+
+func checkDeleteCondition(stmt *gorm.Statement, ts *schema.Field, expr clause.Expression) (bool, error) {
+	switch v := expr.(type) {
+	case clause.Eq:
+		if rv := reflect.ValueOf(v.Value); (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8 {
+			return checkDeleteColumn(stmt, ts, v.Column, "IN")
+		}
+		return checkDeleteColumn(stmt, ts, v.Column, "=")
+	case clause.Gt:
+		return checkDeleteColumn(stmt, ts, v.Column, ">")
+	case clause.Gte:
+		return checkDeleteColumn(stmt, ts, v.Column, ">=")
+	case clause.Lt:
+		return checkDeleteColumn(stmt, ts, v.Column, "<")
+	case clause.Lte:
+		return checkDeleteColumn(stmt, ts, v.Column, "<=")
+	case clause.IN:
+		return checkDeleteColumn(stmt, ts, v.Column, "IN")
+	case clause.AndConditions:
+		return checkDeleteConditions(stmt, ts, v.Exprs)
+	case clause.Expr:
+		return checkDeleteExpr(stmt, ts, v)
+	case clause.NamedExpr:
+		if stmt.DB.AllowGlobalUpdate {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: named condition %q needs AllowGlobalUpdate", ErrDelete, v.SQL)
+	case clause.Neq:
+		return false, fmt.Errorf("%w: <> is not a range of the timestamp", ErrDelete)
+	case clause.Like:
+		return false, fmt.Errorf("%w: LIKE is not a range of the timestamp", ErrDelete)
+	case clause.OrConditions:
+		return false, fmt.Errorf("%w: conditions are joined by AND, not OR", ErrDelete)
+	case clause.NotConditions:
+		return false, fmt.Errorf("%w: NOT is not a range of the timestamp", ErrDelete)
+	}
+	return false, fmt.Errorf("%w: condition %T", ErrDelete, expr)
+}
+
+// checkDeleteColumn checks the operator of a condition on column, it returns true for the timestamp.
+func checkDeleteColumn(stmt *gorm.Statement, ts *schema.Field, column interface{}, op string) (bool, error) {
+	var name string
+	switch c := column.(type) {
+	case clause.Column:
+		name = c.Name
+	case string:
+		parts := splitIdentifier(c)
+		name = parts[len(parts)-1]
+	default:
+		return false, fmt.Errorf("%w: condition on %v", ErrDelete, column)
+	}
+	name = lookUpDBName(stmt, name)
+	if strings.EqualFold(name, ts.DBName) || strings.EqualFold(name, rowTS.Name) {
+		switch op {
+		case "=", "<", "<=", ">", ">=", "IN":
+			return true, nil
+		}
+		return false, fmt.Errorf("%w: %s %s is not a range of the timestamp", ErrDelete, name, op)
+	}
+	if field := lookUpField(stmt, name); (field != nil && isTagField(field)) || strings.EqualFold(name, "tbname") {
+		switch op {
+		case "=", "IN":
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: tags are selected by = and IN, not by %s %s", ErrDelete, name, op)
+	}
+	return false, fmt.Errorf("%w: rows are deleted by the timestamp and tags, not by %s", ErrDelete, name)
+}
+
+// checkDeleteExpr checks a SQL condition such as "ts >= ? AND ts < ? AND location = ?", a condition of the form
+// "column op ?" with op one of =, <, <=, >, >= and IN is checked like the expressions. Other SQL is not parsed,
+// it is only sent with AllowGlobalUpdate and does not bound the timestamp.
+func checkDeleteExpr(stmt *gorm.Statement, ts *schema.Field, expr clause.Expr) (bool, error) {
+	parts := splitAnd(expr.SQL)
+	if len(parts) == len(expr.Vars) {
+		columns, ops := make([]string, len(parts)), make([]string, len(parts))
+		ok := true
+		for i, part := range parts {
+			if columns[i], ops[i], ok = exprCondition(part); !ok {
+				break
+			}
+		}
+		if ok {
+			bounded := false
+			for i, column := range columns {
+				b, err := checkDeleteColumn(stmt, ts, clause.Column{Name: column}, ops[i])
+				if err != nil {
+					return false, err
+				}
+				bounded = bounded || b
+			}
+			return bounded, nil
+		}
+	}
+	if stmt.DB.AllowGlobalUpdate {
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: condition %q is not column op ?, use clause.Gte and the like or AllowGlobalUpdate", ErrDelete, expr.SQL)
+}
+
+// splitAnd splits sql at the ANDs between spaces.
+func splitAnd(sql string) []string {
+	var parts []string
+	upper := strings.ToUpper(sql)
+	if len(upper) != len(sql) {
+		return []string{sql}
+	}
+	for {
+		i := strings.Index(upper, " AND ")
+		if i < 0 {
+			return append(parts, sql)
+		}
+		parts = append(parts, sql[:i])
+		sql, upper = sql[i+len(" AND "):], upper[i+len(" AND "):]
+	}
+}
+
+// exprCondition returns the column and the operator of a "column op ?" or "column IN (?)" condition.
+func exprCondition(sql string) (string, string, bool) {
+	sql = strings.TrimSpace(sql)
+	var op string
+	if strings.HasSuffix(sql, "(?)") {
+		sql = strings.TrimSpace(strings.TrimSuffix(sql, "(?)"))
+	} else if strings.HasSuffix(sql, "?") {
+		sql = strings.TrimSpace(strings.TrimSuffix(sql, "?"))
+		for _, o := range []string{"<=", ">=", "=", "<", ">"} {
+			if strings.HasSuffix(sql, o) {
+				op = o
+				break
+			}
+		}
+	} else {
+		return "", "", false
+	}
+	if op == "" {
+		if len(sql) < 3 || !strings.EqualFold(sql[len(sql)-3:], " IN") {
+			return "", "", false
+		}
+		op = "IN"
+	}
+	column, ok := exprColumn(strings.TrimSpace(sql[:len(sql)-len(op)]))
+	return column, op, ok
+}
+
+// exprColumn returns the name of column, table.column or db.table.column with plain names or names in backticks.
+func exprColumn(str string) (string, bool) {
+	for {
+		var name, rest string
+		if strings.HasPrefix(str, "`") {
+			var ok bool
+			if name, rest, ok = cutQuoted(str); !ok {
+				return "", false
+			}
+		} else {
+			name, rest = str, ""
+			if i := strings.IndexByte(str, '.'); i >= 0 {
+				name, rest = str[:i], str[i:]
+			}
+			if !isPlainName(name) {
+				return "", false
+			}
+		}
+		if rest == "" {
+			return name, true
+		}
+		if rest[0] != '.' {
+			return "", false
+		}
+		str = rest[1:]
+	}
+}
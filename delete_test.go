@@ -0,0 +1,132 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/pseudo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type softReading struct {
+	TS        time.Time
+	Value     int
+	DeletedAt gorm.DeletedAt
+}
+
+func TestDelete(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	db, d := openRecordDB(t, Dialect{})
+	if err := db.Where("ts >= ? AND ts < ?", ts, ts.Add(time.Hour)).Delete(&meter{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("location = ?", "SF").Where(clause.Lt{Column: "ts", Value: ts}).Delete(&meter{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&reading{TS: ts}).Error; err != nil {
+		t.Fatal(err)
+	}
+	result := db.Delete(&[]reading{{TS: ts}, {TS: ts.Add(time.Second)}})
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if err := db.Where(clause.Eq{Column: pseudo.TBName, Value: "d1001"}).Delete(&meter{}, "`ts` >= ?", ts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("group_id IN ? AND meters.ts < ?", []int{1, 2}, ts).Delete(&meter{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where(clause.IN{Column: "ts", Values: []interface{}{ts}}).Delete(&meter{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("ts IN (?)", []time.Time{ts}).Delete(&meter{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	global := db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	if err := global.Delete(&reading{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := global.Where("location = ?", "SF").Delete(&meter{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := global.Where("(group_id IN (?) AND meters.ts < now - 1d)", []int{1, 2}).Delete(&meter{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Unscoped().Where("ts < ?", ts).Delete(&softReading{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"DELETE FROM meters WHERE ts >= '2021-08-11T09:43:00Z' AND ts < '2021-08-11T10:43:00Z'",
+		"DELETE FROM meters WHERE location = 'SF' AND ts < '2021-08-11T09:43:00Z'",
+		"DELETE FROM d1001 WHERE d1001.ts = '2021-08-11T09:43:00Z'",
+		"DELETE FROM d1001 WHERE d1001.ts IN ('2021-08-11T09:43:00Z','2021-08-11T09:43:01Z')",
+		"DELETE FROM meters WHERE tbname = 'd1001' AND `ts` >= '2021-08-11T09:43:00Z'",
+		"DELETE FROM meters WHERE group_id IN (1,2) AND meters.ts < '2021-08-11T09:43:00Z'",
+		"DELETE FROM meters WHERE ts = '2021-08-11T09:43:00Z'",
+		"DELETE FROM meters WHERE ts IN ('2021-08-11T09:43:00Z')",
+		"DELETE FROM d1001",
+		"DELETE FROM meters WHERE location = 'SF'",
+		"DELETE FROM meters WHERE (group_id IN (1,2) AND meters.ts < now - 1d)",
+		"DELETE FROM soft_readings WHERE ts < '2021-08-11T09:43:00Z'",
+	)
+
+	// the timestamps of a slice are split by maxSQLLength like the rows of an insert
+	db, d = openRecordDB(t, Dialect{MaxSQLLength: 90})
+	result = db.Delete(&[]reading{{TS: ts}, {TS: ts.Add(time.Second)}, {TS: ts.Add(2 * time.Second)}})
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.RowsAffected != 2 {
+		t.Errorf("expect 2 rows affected got %d", result.RowsAffected)
+	}
+	d.AssertExecs(t,
+		"DELETE FROM d1001 WHERE d1001.ts IN ('2021-08-11T09:43:00Z','2021-08-11T09:43:01Z')",
+		"DELETE FROM d1001 WHERE d1001.ts = '2021-08-11T09:43:02Z'",
+	)
+
+	db, d = openRecordDB(t, Dialect{})
+	for _, query := range []*gorm.DB{
+		db.Where("location = ?", "SF"),
+		db.Where(clause.Eq{Column: pseudo.TBName, Value: "d1001"}),
+		db.Where("location IN ?", []string{"SF", "LA"}),
+	} {
+		if err := query.Delete(&meter{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+			t.Errorf("expect ErrMissingWhereClause without a timestamp bound got %v", err)
+		}
+	}
+	if err := db.Delete(&reading{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expect ErrMissingWhereClause got %v", err)
+	}
+	for _, query := range []*gorm.DB{
+		db.Where("voltage > ?", 200),
+		db.Where("ts < ? AND voltage > ?", ts, 200),
+		db.Where("ts < current"),
+		db.Where("ts <> ?", ts),
+		db.Where("ts != ?", ts),
+		db.Where("ts LIKE ?", "2021%"),
+		db.Where("ts BETWEEN ? AND ?", ts, ts),
+		db.Where("ts < now - 1d"),
+		db.Where("ts < @ts", map[string]interface{}{"ts": ts}),
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Where("voltage > ?", 200),
+		db.Where("ts < ? OR location = ?", ts, "SF"),
+		db.Where("NOT ts < ?", ts),
+		db.Where("ts < ?", ts).Or("ts > ?", ts),
+		db.Where("ts < ?", ts).Not("location = ?", "SF"),
+		db.Where(clause.Neq{Column: "ts", Value: ts}),
+		db.Where(clause.Like{Column: "location", Value: "S%"}),
+		db.Where(clause.Gt{Column: "location", Value: "S"}).Where("ts < ?", ts),
+	} {
+		if err := query.Delete(&meter{}).Error; !errors.Is(err, ErrDelete) {
+			t.Errorf("%v: expect ErrDelete got %v", query.Statement.Clauses["WHERE"].Expression, err)
+		}
+	}
+	if err := db.Where("ts < ?", ts).Delete(&softReading{}).Error; !errors.Is(err, ErrDelete) {
+		t.Errorf("expect ErrDelete for a soft delete model got %v", err)
+	}
+	if err := db.Table("d1001").Where("ts < ?", ts).Delete(map[string]interface{}{}).Error; !errors.Is(err, ErrDelete) {
+		t.Errorf("expect ErrDelete without a model got %v", err)
+	}
+	d.AssertExecs(t)
+}
@@ -0,0 +1,163 @@
+package tdengine_gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordDriver is a database/sql driver that records every statement instead of sending it to a server.
+// Queries are answered from results, keyed by the statement after parameter interpolation.
+type recordDriver struct {
+	mu      sync.Mutex
+	execs   []string
+	results map[string]*recordRows
+	errs    map[string]error
+}
+
+type recordRows struct {
+	columns []string
+	values  [][]driver.Value
+	index   int
+}
+
+var recordDrivers sync.Map
+
+func init() {
+	sql.Register("tdengine_record", recordOpener{})
+}
+
+type recordOpener struct{}
+
+func (recordOpener) Open(name string) (driver.Conn, error) {
+	d, ok := recordDrivers.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown record driver %s", name)
+	}
+	return &recordConn{d: d.(*recordDriver)}, nil
+}
+
+// openRecordDB opens a gorm DB backed by a new recordDriver.
+func openRecordDB(t testing.TB, dialect Dialect) (*gorm.DB, *recordDriver) {
+	d := &recordDriver{results: map[string]*recordRows{}, errs: map[string]error{}}
+	recordDrivers.Store(t.Name(), d)
+	t.Cleanup(func() { recordDrivers.Delete(t.Name()) })
+	conn, err := sql.Open("tdengine_record", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	dialect.Conn = conn
+	db, err := gorm.Open(dialect, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, d
+}
+
+// Result registers the rows returned by query.
+func (d *recordDriver) Result(query string, columns []string, values ...[]driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.results[query] = &recordRows{columns: columns, values: values}
+}
+
+// Error makes executing query fail with err.
+func (d *recordDriver) Error(query string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.errs[query] = err
+}
+
+// Execs returns the statements executed so far.
+func (d *recordDriver) Execs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.execs...)
+}
+
+func (d *recordDriver) AssertExecs(t *testing.T, expect ...string) {
+	t.Helper()
+	got := d.Execs()
+	if strings.Join(got, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("expect statements\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+type recordConn struct {
+	d *recordDriver
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *recordConn) Close() error {
+	return nil
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *recordConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query, err := interpolate(query, args)
+	if err != nil {
+		return nil, err
+	}
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	query = strings.TrimSpace(query)
+	c.d.execs = append(c.d.execs, query)
+	if err, ok := c.d.errs[query]; ok {
+		return nil, err
+	}
+	return driver.RowsAffected(strings.Count(query, "),(") + 1), nil
+}
+
+func (c *recordConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query, err := interpolate(query, args)
+	if err != nil {
+		return nil, err
+	}
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	result, ok := c.d.results[query]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	return &recordRows{columns: result.columns, values: result.values}, nil
+}
+
+// interpolate fails for args, the dialect writes values as literals and sends statements without args.
+func interpolate(query string, args []driver.NamedValue) (string, error) {
+	if len(args) != 0 {
+		return "", fmt.Errorf("unexpected %d args for %s", len(args), query)
+	}
+	return query, nil
+}
+
+func (r *recordRows) Columns() []string {
+	return r.columns
+}
+
+func (r *recordRows) Close() error {
+	return nil
+}
+
+func (r *recordRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.index])
+	r.index++
+	return nil
+}
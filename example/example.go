@@ -1,10 +1,11 @@
 package main
 
 import (
-	"database/sql"
 	"github.com/taosdata/tdengine_gorm"
 	"github.com/taosdata/tdengine_gorm/clause/create"
+	"github.com/taosdata/tdengine_gorm/clause/database"
 	"github.com/taosdata/tdengine_gorm/clause/fill"
+	"github.com/taosdata/tdengine_gorm/clause/function"
 	"github.com/taosdata/tdengine_gorm/clause/using"
 	"github.com/taosdata/tdengine_gorm/clause/window"
 	"gorm.io/gorm"
@@ -17,6 +18,26 @@ import (
 type Data struct {
 	TS    time.Time
 	Value float64
+	Tbn   string `gorm:"tag"`
+}
+
+// Reading is a row of a subtable of stb_1, Create inserts it with USING stb_1 TAGS (tbn), TagValues makes it a TagValuer
+type Reading struct {
+	TS       time.Time
+	Value    float64
+	SubTable string `gorm:"-"`
+}
+
+func (r Reading) STableName() string {
+	return "stb_1"
+}
+
+func (r Reading) SubTableName() string {
+	return r.SubTable
+}
+
+func (r Reading) TagValues() map[string]interface{} {
+	return map[string]interface{}{"tbn": r.SubTable}
 }
 
 func main() {
@@ -39,22 +60,22 @@ func main() {
 	t1 := now.Add(time.Second)
 	randValue2 := rand.Float64()
 
-	//INSERT INTO tb_2 USING stb_1('tbn') TAGS('tb_2') (ts,value) VALUES ('2021-08-11 09:43:01.041',0.940509)
+	//INSERT INTO tb_2 USING stb_1(tbn) TAGS('tb_2') (ts,value) VALUES ('2021-08-11 09:43:01.041',0.940509)
 	automaticTableCreationWhenInsertingData(db, "tb_2", t1, randValue2)
-	//SELECT * FROM tb_1 WHERE ts = '2021-08-11 09:43:00.041'
+	//SELECT ts,value,tbn FROM tb_1 WHERE ts = '2021-08-11 09:43:00.041'
 	tb1Data := queryData(db, "tb_1", now)
 	if tb1Data.Value != randValue {
 		log.Fatalf("expect value %v got %v", randValue, tb1Data.Value)
 	}
-	//SELECT * FROM tb_2 WHERE ts = '2021-08-11 09:43:01.041'
+	//SELECT ts,value,tbn FROM tb_2 WHERE ts = '2021-08-11 09:43:01.041'
 	tb2Data := queryData(db, "tb_2", t1)
 	if tb2Data.Value != randValue2 {
 		log.Fatalf("expect value %v got %v", randValue, tb2Data.Value)
 	}
-	//SELECT * FROM stb_1 WHERE ts = '2021-08-11 09:43:00.041'
+	//SELECT ts,value,tbn FROM stb_1 WHERE ts = '2021-08-11 09:43:00.041'
 	stbData := queryData(db, "stb_1", now)
-	if stbData.Value != randValue {
-		log.Fatalf("expect value %v got %v", randValue, stbData.Value)
+	if stbData.Value != randValue || stbData.Tbn != "tb_1" {
+		log.Fatalf("expect value %v of tb_1 got %v of %s", randValue, stbData.Value, stbData.Tbn)
 	}
 	t2 := now.Add(time.Second * 2)
 	t3 := now.Add(time.Second * 3)
@@ -63,7 +84,7 @@ func main() {
 	v2 := 12
 	v3 := 13
 
-	//INSERT INTO tb_aggregate USING stb_1('tbn') TAGS('tb_aggregate') (ts,value) VALUES ('2021-08-11 09:43:01.041',11),('2021-08-11 09:43:02.041',12),('2021-08-11 09:43:03.041',13)
+	//INSERT INTO tb_aggregate USING stb_1(tbn) TAGS('tb_aggregate') (ts,value) VALUES ('2021-08-11 09:43:01.041',11),('2021-08-11 09:43:02.041',12),('2021-08-11 09:43:03.041',13)
 	automaticTableCreationWhenInsertingMultiData(db, "tb_aggregate", []map[string]interface{}{
 		{
 			"ts":    t1,
@@ -77,8 +98,8 @@ func main() {
 		},
 	})
 	//aggregate query
-	//SELECT avg(value) as v FROM tb_aggregate WHERE ts >= '2021-08-11 09:43:01.041' and ts <= '2021-08-11 09:43:03.041'
-	resultAvg := aggregateQuery(db, "tb_aggregate", "avg(value) as v", t1, t3, nil)
+	//SELECT AVG(value) AS v FROM tb_aggregate WHERE ts >= '2021-08-11 09:43:01.041' and ts <= '2021-08-11 09:43:03.041'
+	resultAvg := aggregateQuery(db, "tb_aggregate", function.Select(function.Avg("value").As("v")), t1, t3, nil)
 	expectAvg := []map[string]interface{}{
 		{
 			"v": float64(12),
@@ -92,7 +113,7 @@ func main() {
 		log.Fatal(err)
 	}
 	//SELECT max(value) as v FROM tb_aggregate WHERE ts >= '2021-08-11 09:43:01.041' and ts <= '2021-08-11 09:43:04.041' INTERVAL(1000000u) FILL (NULL)
-	resultWindowMax := aggregateQuery(db, "tb_aggregate", "max(value) as v", t1, t4, []clause.Expression{
+	resultWindowMax := aggregateQuery(db, "tb_aggregate", function.Select("max(value) as v"), t1, t4, []clause.Expression{
 		window.SetInterval(*windowD),
 		fill.SetFill(fill.FillNull),
 	})
@@ -121,17 +142,15 @@ func main() {
 
 func createDatabase() {
 	dsnWithoutDB := "root:taosdata@/tcp(127.0.0.1:6030)/?loc=Local"
-	nativeDB, err := sql.Open(tdengine_gorm.DriverName, dsnWithoutDB)
+	db, err := gorm.Open(tdengine_gorm.Open(dsnWithoutDB))
 	if err != nil {
 		log.Fatalf("connect db error:%v", err)
-		return
 	}
-	_, err = nativeDB.Exec("create database if not exists gorm_test")
+	//CREATE DATABASE IF NOT EXISTS gorm_test
+	err = db.Migrator().(tdengine_gorm.Migrator).CreateDatabase("gorm_test", true, database.DatabaseOptions{})
 	if err != nil {
 		log.Fatalf("create database error %v", err)
-		return
 	}
-	_ = nativeDB.Close()
 }
 
 func connect() *gorm.DB {
@@ -187,14 +206,9 @@ func insertData(db *gorm.DB, tableName string, ts time.Time, value interface{})
 	}
 }
 
-func automaticTableCreationWhenInsertingData(db *gorm.DB, tableName string, ts time.Time, value interface{}) {
-	//automatic table creation when inserting data
-	err := db.Table(tableName).Clauses(using.SetUsing("stb_1", map[string]interface{}{
-		"tbn": tableName,
-	})).Create(map[string]interface{}{
-		"ts":    ts,
-		"value": value,
-	}).Error
+func automaticTableCreationWhenInsertingData(db *gorm.DB, tableName string, ts time.Time, value float64) {
+	//automatic table creation when inserting data, Reading implements tdengine_gorm.SubTableModel
+	err := db.Create(&Reading{TS: ts, Value: value, SubTable: tableName}).Error
 	if err != nil {
 		log.Fatalf("create table when insert data error %v", err)
 	}
@@ -219,9 +233,9 @@ func automaticTableCreationWhenInsertingMultiData(db *gorm.DB, tableName string,
 	}
 }
 
-func aggregateQuery(db *gorm.DB, tableName string, query string, start, end time.Time, conds []clause.Expression) []map[string]interface{} {
+func aggregateQuery(db *gorm.DB, tableName string, query clause.Expression, start, end time.Time, conds []clause.Expression) []map[string]interface{} {
 	var result []map[string]interface{}
-	err := db.Table(tableName).Select(query).Where("ts >= ? and ts <= ?", start, end).Clauses(conds...).Find(&result).Error
+	err := db.Table(tableName).Clauses(query).Where("ts >= ? and ts <= ?", start, end).Clauses(conds...).Find(&result).Error
 	if err != nil {
 		log.Fatalf("aggregate query error %v", err)
 	}
@@ -0,0 +1,162 @@
+package tdengine_gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLiteral is returned for a value that cannot be written as a TDengine literal.
+var ErrLiteral = errors.New("unsupported literal")
+
+// QuoteString quotes s as a NCHAR or BINARY literal, ' and \ are escaped with \.
+// s must not contain a NUL byte, the server reads the statement as a C string.
+func QuoteString(s string) (string, error) {
+	if strings.IndexByte(s, 0) >= 0 {
+		return "", fmt.Errorf("%w: string contains NUL byte", ErrLiteral)
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('\'')
+	return b.String(), nil
+}
+
+// Literal formats v as a TDengine literal for a millisecond database, see Dialect.Literal.
+func Literal(v interface{}) (string, error) {
+	return Dialect{}.Literal(v)
+}
+
+// Literal formats v as a TDengine literal. nil and nil pointers are NULL, strings and []byte are quoted with QuoteString
+// and time.Time is written at the precision of the dialect, see Dialect.EpochTime.
+func (dialect Dialect) Literal(v interface{}) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		v = value
+	}
+	switch value := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return QuoteString(value)
+	case []byte:
+		return QuoteString(string(value))
+	case time.Time:
+		return dialect.timeLiteral(value), nil
+	case bool:
+		if value {
+			return "1", nil
+		}
+		return "0", nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return dialect.Literal(rv.Elem().Interface())
+	case reflect.Bool:
+		return dialect.Literal(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("%w: %v", ErrLiteral, f)
+		}
+		bitSize := 64
+		if rv.Kind() == reflect.Float32 {
+			bitSize = 32
+		}
+		return strconv.FormatFloat(f, 'g', -1, bitSize), nil
+	case reflect.String:
+		return QuoteString(rv.String())
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return QuoteString(string(rv.Bytes()))
+		}
+	}
+	return "", fmt.Errorf("%w: %T", ErrLiteral, v)
+}
+
+// explain replaces the ? outside of string literals and names in backticks with the literals of vars.
+func (dialect Dialect) explain(writer clause.Writer, sql string, vars []interface{}) error {
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' && i+1 < len(sql) {
+				writer.WriteByte(c)
+				i++
+				c = sql[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?' && len(vars) > 0:
+			literal, err := dialect.Literal(vars[0])
+			if err != nil {
+				return err
+			}
+			writer.WriteString(literal)
+			vars = vars[1:]
+			continue
+		}
+		writer.WriteByte(c)
+	}
+	return nil
+}
+
+// literalConnPool sends statements without their vars. BindVarTo writes the values into the statement as literals
+// and keeps them in Statement.Vars, so the driver must not interpolate them a second time.
+type literalConnPool struct {
+	gorm.ConnPool
+}
+
+func (pool literalConnPool) ExecContext(ctx context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	return pool.ConnPool.ExecContext(ctx, query)
+}
+
+func (pool literalConnPool) QueryContext(ctx context.Context, query string, _ ...interface{}) (*sql.Rows, error) {
+	return pool.ConnPool.QueryContext(ctx, query)
+}
+
+func (pool literalConnPool) QueryRowContext(ctx context.Context, query string, _ ...interface{}) *sql.Row {
+	return pool.ConnPool.QueryRowContext(ctx, query)
+}
+
+// GetDBConn returns the *sql.DB of the wrapped pool for gorm.DB.DB.
+func (pool literalConnPool) GetDBConn() (*sql.DB, error) {
+	if db, ok := pool.ConnPool.(*sql.DB); ok {
+		return db, nil
+	}
+	if connector, ok := pool.ConnPool.(gorm.GetDBConnector); ok {
+		return connector.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
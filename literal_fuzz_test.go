@@ -0,0 +1,69 @@
+//go:build go1.18
+// +build go1.18
+
+package tdengine_gorm
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+var fuzzStrings = []string{"", "SF", "it's", `\`, `\'`, `'\`, "''", "?", "' OR '1'='1", "'); DROP DATABASE power; --", "北京", "\n\t\r", "\xff"}
+
+// FuzzQuoteString checks that a quoted string is read back by the tokenizer as exactly one literal with the same value.
+func FuzzQuoteString(f *testing.F) {
+	for _, s := range fuzzStrings {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		quoted, err := QuoteString(s)
+		if strings.IndexByte(s, 0) >= 0 {
+			if err == nil {
+				t.Fatalf("expect error for NUL byte in %q", s)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, value, ok := scanString(quoted + " AND ts > 0")
+		if !ok || n != len(quoted) || value != s {
+			t.Fatalf("%q quoted as %s is read back as %q (%d of %d bytes)", s, quoted, value, n, len(quoted))
+		}
+	})
+}
+
+// FuzzBindVarTo checks that the executed statement and Explain agree for any string value.
+func FuzzBindVarTo(f *testing.F) {
+	for _, s := range fuzzStrings {
+		f.Add(s)
+	}
+	db, _ := openRecordDB(f, Dialect{})
+	db = db.Session(&gorm.Session{DryRun: true})
+	f.Fuzz(func(t *testing.T, s string) {
+		if strings.IndexByte(s, 0) >= 0 {
+			return
+		}
+		stmt := db.Where("location = ?", s).Find(&[]meter{}).Statement
+		if stmt.Error != nil {
+			t.Fatal(stmt.Error)
+		}
+		sql := stmt.SQL.String()
+		if len(stmt.Vars) != 1 || stmt.Vars[0] != s {
+			t.Fatalf("expect the bound var got %v", stmt.Vars)
+		}
+		if explained := db.Dialector.Explain("SELECT ts,current,voltage,location,group_id FROM meters WHERE location = ?", s); explained != sql {
+			t.Fatalf("executed %s explained %s", sql, explained)
+		}
+		prefix := "SELECT ts,current,voltage,location,group_id FROM meters WHERE location = "
+		if !strings.HasPrefix(sql, prefix) {
+			t.Fatalf("unexpected statement %s", sql)
+		}
+		n, value, ok := scanString(sql[len(prefix):])
+		if !ok || len(prefix)+n != len(sql) || value != s {
+			t.Fatalf("%q bound as %s", s, sql)
+		}
+	})
+}
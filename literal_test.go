@@ -0,0 +1,123 @@
+package tdengine_gorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestLiteral(t *testing.T) {
+	location := "SF"
+	var nilLocation *string
+	tests := []struct {
+		value  interface{}
+		expect string
+	}{
+		{nil, "NULL"},
+		{nilLocation, "NULL"},
+		{&location, "'SF'"},
+		{"it's", `'it\'s'`},
+		{`C:\data\`, `'C:\\data\\'`},
+		{"'); DROP DATABASE power; --", `'\'); DROP DATABASE power; --'`},
+		{[]byte(`a'b`), `'a\'b'`},
+		{time.Date(2021, 8, 11, 9, 43, 0, 1500500, time.UTC), "'2021-08-11T09:43:00.001Z'"},
+		{true, "1"},
+		{int8(-3), "-3"},
+		{uint64(math.MaxUint64), "18446744073709551615"},
+		{float32(10.3), "10.3"},
+		{10.3, "10.3"},
+		{sql.NullString{String: "a'b", Valid: true}, `'a\'b'`},
+		{sql.NullInt64{}, "NULL"},
+	}
+	for _, test := range tests {
+		literal, err := Literal(test.value)
+		if err != nil {
+			t.Errorf("%#v: %v", test.value, err)
+			continue
+		}
+		if literal != test.expect {
+			t.Errorf("%#v: expect %s got %s", test.value, test.expect, literal)
+		}
+	}
+	for _, value := range []interface{}{"a\x00b", math.NaN(), math.Inf(1), struct{}{}} {
+		if _, err := Literal(value); !errors.Is(err, ErrLiteral) {
+			t.Errorf("%#v: expect ErrLiteral got %v", value, err)
+		}
+	}
+}
+
+func TestBindVarTo(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	name := `d1001' OR '1'='1`
+	d.Result(`SHOW TABLES LIKE 'd1001\' OR \'1\'=\'1'`, showColumns)
+	var tables []map[string]interface{}
+	if err := db.Raw("SHOW TABLES LIKE ?", name).Scan(&tables).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	stmt := db.Session(&gorm.Session{DryRun: true}).Where("location = ? AND note = ?", "it's", "what?").Find(&[]meter{}).Statement
+	expect := `SELECT ts,current,voltage,location,group_id FROM meters WHERE location = 'it\'s' AND note = 'what?'`
+	if stmt.SQL.String() != expect || !reflect.DeepEqual(stmt.Vars, []interface{}{"it's", "what?"}) {
+		t.Errorf("expect %s with the bound vars got %s %v", expect, stmt.SQL.String(), stmt.Vars)
+	}
+	if explained := db.Dialector.Explain("SELECT ts,current,voltage,location,group_id FROM meters WHERE location = ? AND note = ?", "it's", "what?"); explained != expect {
+		t.Errorf("expect %s got %s", expect, explained)
+	}
+	if explained := db.Dialector.Explain("SELECT ts,current,voltage,location,group_id FROM meters WHERE note = 'why?' AND location = ?", "SF"); explained != "SELECT ts,current,voltage,location,group_id FROM meters WHERE note = 'why?' AND location = 'SF'" {
+		t.Errorf("unexpected explain %s", explained)
+	}
+	if explained := db.Dialector.Explain("SELECT `what?` FROM meters WHERE location = ?", "SF"); explained != "SELECT `what?` FROM meters WHERE location = 'SF'" {
+		t.Errorf("unexpected explain %s", explained)
+	}
+	for _, value := range []interface{}{"a\x00b", math.NaN(), math.Inf(1), errValuer{}} {
+		stmt := db.Session(&gorm.Session{DryRun: true}).Where("location = ?", value).Find(&[]meter{}).Statement
+		if !errors.Is(stmt.Error, ErrLiteral) {
+			t.Errorf("%v: expect ErrLiteral got %v", value, stmt.Error)
+		}
+		if strings.Contains(stmt.SQL.String(), "NULL") {
+			t.Errorf("%v: unexpected NULL in %s", value, stmt.SQL.String())
+		}
+	}
+	if err := db.Where("location = ?", "a\x00b").Find(&[]meter{}).Error; !errors.Is(err, ErrLiteral) {
+		t.Errorf("expect ErrLiteral got %v", err)
+	}
+}
+
+type errValuer struct{}
+
+func (errValuer) Value() (driver.Value, error) {
+	return nil, errors.New("no value")
+}
+
+// scanString mirrors the string token of the TDengine tokenizer, it returns the length of the quoted literal
+// at the start of sql and its value after escapes.
+func scanString(sql string) (int, string, bool) {
+	quote := sql[0]
+	var b strings.Builder
+	for i := 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			i++
+			if i < len(sql) {
+				b.WriteByte(sql[i])
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				b.WriteByte(quote)
+				i++
+				continue
+			}
+			return i + 1, b.String(), true
+		default:
+			b.WriteByte(sql[i])
+		}
+	}
+	return 0, "", false
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -11,6 +13,44 @@ import (
 	"gorm.io/gorm/schema"
 )
 
+var (
+	// ErrUnsafeMigration is returned when the model asks for a schema change that TDengine cannot apply without losing data.
+	ErrUnsafeMigration = errors.New("unsafe migration")
+	// ErrNarrowColumn is returned when a BINARY/NCHAR column or tag would get shorter, it wraps ErrUnsafeMigration.
+	ErrNarrowColumn = fmt.Errorf("%w: BINARY/NCHAR length cannot be narrowed", ErrUnsafeMigration)
+	// ErrChangeColumnType is returned when a column or tag would change its type, it wraps ErrUnsafeMigration.
+	ErrChangeColumnType = fmt.Errorf("%w: type cannot be changed", ErrUnsafeMigration)
+	// ErrSwitchColumnKind is returned when a data column would become a tag or the other way, it wraps ErrUnsafeMigration.
+	ErrSwitchColumnKind = fmt.Errorf("%w: cannot switch between column and tag", ErrUnsafeMigration)
+	// ErrSubTableSchema is returned when altering a subtable, its schema belongs to the supertable.
+	ErrSubTableSchema = errors.New("schema of a subtable cannot be altered")
+	// ErrTagOnNormalTable is returned when adding a tag to a normal table.
+	ErrTagOnNormalTable = errors.New("normal table has no tags")
+	// ErrDropTimestamp is returned when dropping the first timestamp column.
+	ErrDropTimestamp = errors.New("the first timestamp column cannot be dropped")
+	// ErrRenameColumn is returned when renaming a data column, TDengine can only rename tags.
+	ErrRenameColumn = errors.New("RenameColumn not support for data columns")
+	// ErrTableNotExist is returned when altering a table that does not exist.
+	ErrTableNotExist = errors.New("table does not exist")
+)
+
+// TableType is the kind of table found by the migrator.
+type TableType int
+
+const (
+	STable TableType = iota + 1
+	NormalTable
+	SubTable
+)
+
+// keyword is the word used in ALTER statements, subtables are altered as tables.
+func (t TableType) keyword() string {
+	if t == STable {
+		return "STABLE"
+	}
+	return "TABLE"
+}
+
 type Migrator struct {
 	migrator.Migrator
 	d Dialect
@@ -24,6 +64,7 @@ type Column struct {
 	precision         sql.NullInt64
 	scale             sql.NullInt64
 	datetimeprecision sql.NullInt64
+	note              string
 }
 
 func (c Column) Name() string {
@@ -57,25 +98,158 @@ func (c Column) DecimalSize() (precision int64, scale int64, ok bool) {
 	return
 }
 
+// Note returns the note column of DESCRIBE, "TAG" for tags.
+func (c Column) Note() string {
+	return c.note
+}
+
+// IsTag reports whether the column is a tag of a supertable or subtable.
+func (c Column) IsTag() bool {
+	return c.note == "TAG"
+}
+
 func (m Migrator) FullDataTypeOf(field *schema.Field) (expr clause.Expr) {
 	expr.SQL = m.d.DataTypeOf(field)
 	return
 }
 
+// HasTable checks SHOW STABLES and SHOW TABLES, subtables and normal tables are both reported.
+func (m Migrator) HasTable(value interface{}) bool {
+	tableType, _ := m.TableType(value)
+	return tableType != 0
+}
+
+// TableType returns whether the table is a supertable, a normal table or a subtable, 0 if it does not exist.
+func (m Migrator) TableType(value interface{}) (tableType TableType, err error) {
+	err = m.RunWithValue(value, func(stmt *gorm.Statement) (err error) {
+		tableType, err = m.tableType(stmt.Table)
+		return err
+	})
+	return
+}
+
+// HasColumn checks the columns and tags listed by DESCRIBE.
+func (m Migrator) HasColumn(value interface{}, field string) bool {
+	var exists bool
+	_ = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		columns, err := m.describe(stmt.Table)
+		if err != nil {
+			return err
+		}
+		_, exists = findColumn(columns, lookUpDBName(stmt, field))
+		return nil
+	})
+	return exists
+}
+
+// ColumnTypes returns the columns and tags listed by DESCRIBE, the elements are Column.
+func (m Migrator) ColumnTypes(value interface{}) (columnTypes []gorm.ColumnType, err error) {
+	err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		columns, err := m.describe(stmt.Table)
+		if err != nil {
+			return err
+		}
+		columnTypes = make([]gorm.ColumnType, 0, len(columns))
+		for _, column := range columns {
+			columnTypes = append(columnTypes, column)
+		}
+		return nil
+	})
+	return
+}
+
+// AddColumn adds a column or, for fields tagged with `gorm:"tag"`, a tag.
+func (m Migrator) AddColumn(value interface{}, field string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		f := lookUpField(stmt, field)
+		if f == nil {
+			return fmt.Errorf("failed to look up field with name: %s", field)
+		}
+		tableType, err := m.alterableTableType(stmt.Table)
+		if err != nil {
+			return err
+		}
+		return m.addColumn(stmt, tableType, f)
+	})
+}
+
+// AlterColumn widens a BINARY/NCHAR column or tag to the length of the field.
+// TDengine can not change anything else, other changes return an error wrapping ErrUnsafeMigration.
 func (m Migrator) AlterColumn(value interface{}, field string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		if field := stmt.Schema.LookUpField(field); field != nil {
-			return m.DB.Exec(
-				"ALTER TABLE ? MODIFY COLUMN ? ?",
-				clause.Table{Name: stmt.Table}, clause.Column{Name: field.DBName}, m.FullDataTypeOf(field),
-			).Error
+		f := lookUpField(stmt, field)
+		if f == nil {
+			return fmt.Errorf("failed to look up field with name: %s", field)
+		}
+		tableType, err := m.alterableTableType(stmt.Table)
+		if err != nil {
+			return err
+		}
+		columns, err := m.describe(stmt.Table)
+		if err != nil {
+			return err
+		}
+		column, ok := findColumn(columns, f.DBName)
+		if !ok {
+			return fmt.Errorf("column %s does not exist in %s", f.DBName, stmt.Table)
+		}
+		return m.alterColumn(stmt, tableType, column, f)
+	})
+}
+
+// DropColumn drops a column or tag, whichever DESCRIBE reports.
+func (m Migrator) DropColumn(value interface{}, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		name := lookUpDBName(stmt, name)
+		tableType, err := m.alterableTableType(stmt.Table)
+		if err != nil {
+			return err
+		}
+		columns, err := m.describe(stmt.Table)
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("failed to look up field with name: %s", field)
+		column, ok := findColumn(columns, name)
+		if !ok {
+			return fmt.Errorf("column %s does not exist in %s", name, stmt.Table)
+		}
+		if columns[0].name == column.name {
+			return fmt.Errorf("%w: %s.%s", ErrDropTimestamp, stmt.Table, column.name)
+		}
+		kind := "COLUMN"
+		if column.IsTag() {
+			kind = "TAG"
+		}
+		return m.DB.Exec(
+			"ALTER "+tableType.keyword()+" ? DROP "+kind+" ?",
+			clause.Table{Name: stmt.Table}, clause.Column{Name: column.name},
+		).Error
 	})
 }
 
+// RenameColumn renames a tag with CHANGE TAG, oldName or newName must be a field tagged with `gorm:"tag"`.
+// Data columns can not be renamed and return ErrRenameColumn.
 func (m Migrator) RenameColumn(value interface{}, oldName, newName string) error {
-	return errors.New("RenameColumn not support")
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		field := lookUpField(stmt, newName)
+		if field == nil {
+			field = lookUpField(stmt, oldName)
+		}
+		if field == nil || !isTagField(field) {
+			return fmt.Errorf("%w: %s.%s", ErrRenameColumn, stmt.Table, oldName)
+		}
+		tableType, err := m.alterableTableType(stmt.Table)
+		if err != nil {
+			return err
+		}
+		if tableType != STable {
+			return fmt.Errorf("%w: %s.%s", ErrTagOnNormalTable, stmt.Table, oldName)
+		}
+		return m.DB.Exec(
+			"ALTER STABLE ? CHANGE TAG ? ?",
+			clause.Table{Name: stmt.Table}, clause.Column{Name: lookUpDBName(stmt, oldName)}, clause.Column{Name: lookUpDBName(stmt, newName)},
+		).Error
+	})
 }
 
 func (m Migrator) RenameIndex(value interface{}, oldName, newName string) error {
@@ -85,6 +259,282 @@ func (m Migrator) RenameIndex(value interface{}, oldName, newName string) error
 func (m Migrator) DropConstraint(value interface{}, name string) error {
 	return errors.New("DropConstraint not support")
 }
+
+// AutoMigrate creates a supertable for every model, fields tagged with `gorm:"tag"` become TAGS and the others become columns.
+// A model without tag fields creates a normal table.
+// When the table already exists, missing columns and tags are added and BINARY/NCHAR lengths are widened,
+// any other change returns ErrUnsafeMigration and existing columns are never dropped.
 func (m Migrator) AutoMigrate(values ...interface{}) error {
-	return errors.New("AutoMigrate not support")
+	for _, value := range values {
+		if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			if stmt.Schema == nil {
+				return fmt.Errorf("AutoMigrate needs a model, got %v", value)
+			}
+			tableType, err := m.tableType(stmt.Table)
+			if err != nil {
+				return err
+			}
+			switch tableType {
+			case 0:
+				return m.createTable(stmt)
+			case SubTable:
+				return fmt.Errorf("%w: %s", ErrSubTableSchema, stmt.Table)
+			}
+			columns, err := m.describe(stmt.Table)
+			if err != nil {
+				return err
+			}
+			return m.migrateTable(stmt, tableType, columns)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Migrator) createTable(stmt *gorm.Statement) error {
+	var (
+		columnSQL []string
+		tagSQL    []string
+		columns   []interface{}
+		tags      []interface{}
+	)
+	for _, dbName := range stmt.Schema.DBNames {
+		field := stmt.Schema.FieldsByDBName[dbName]
+		if field.IgnoreMigration {
+			continue
+		}
+		if isTagField(field) {
+			tagSQL = append(tagSQL, "? ?")
+			tags = append(tags, clause.Column{Name: dbName}, m.FullDataTypeOf(field))
+			continue
+		}
+		if len(columnSQL) == 0 && field.DataType != schema.Time {
+			return fmt.Errorf("the first column of %s must be a timestamp, got %s", stmt.Table, field.Name)
+		}
+		columnSQL = append(columnSQL, "? ?")
+		columns = append(columns, clause.Column{Name: dbName}, m.FullDataTypeOf(field))
+	}
+	if len(columnSQL) == 0 {
+		return fmt.Errorf("%s has no timestamp column", stmt.Table)
+	}
+	values := append([]interface{}{clause.Table{Name: stmt.Table}}, columns...)
+	if len(tagSQL) == 0 {
+		return m.DB.Exec("CREATE TABLE IF NOT EXISTS ? ("+strings.Join(columnSQL, ",")+")", values...).Error
+	}
+	return m.DB.Exec(
+		"CREATE STABLE IF NOT EXISTS ? ("+strings.Join(columnSQL, ",")+") TAGS ("+strings.Join(tagSQL, ",")+")",
+		append(values, tags...)...,
+	).Error
+}
+
+func (m Migrator) migrateTable(stmt *gorm.Statement, tableType TableType, columns []Column) error {
+	for _, dbName := range stmt.Schema.DBNames {
+		field := stmt.Schema.FieldsByDBName[dbName]
+		if field.IgnoreMigration {
+			continue
+		}
+		var err error
+		if column, ok := findColumn(columns, dbName); ok {
+			err = m.alterColumn(stmt, tableType, column, field)
+		} else {
+			err = m.addColumn(stmt, tableType, field)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Migrator) addColumn(stmt *gorm.Statement, tableType TableType, field *schema.Field) error {
+	kind := "COLUMN"
+	if isTagField(field) {
+		if tableType != STable {
+			return fmt.Errorf("%w: %s.%s", ErrTagOnNormalTable, stmt.Table, field.DBName)
+		}
+		kind = "TAG"
+	}
+	return m.DB.Exec(
+		"ALTER "+tableType.keyword()+" ? ADD "+kind+" ? ?",
+		clause.Table{Name: stmt.Table}, clause.Column{Name: field.DBName}, m.FullDataTypeOf(field),
+	).Error
+}
+
+func (m Migrator) alterColumn(stmt *gorm.Statement, tableType TableType, column Column, field *schema.Field) error {
+	kind := "COLUMN"
+	if isTagField(field) {
+		kind = "TAG"
+	}
+	if (kind == "TAG") != column.IsTag() {
+		return fmt.Errorf("%w: %s.%s", ErrSwitchColumnKind, stmt.Table, field.DBName)
+	}
+	dataType := m.FullDataTypeOf(field)
+	base, length := splitDataType(dataType.SQL)
+	if !strings.EqualFold(base, column.datatype) {
+		return fmt.Errorf("%w: %s.%s from %s to %s", ErrChangeColumnType, stmt.Table, field.DBName, column.datatype, dataType.SQL)
+	}
+	if base != "BINARY" && base != "NCHAR" {
+		return nil
+	}
+	current, _ := column.Length()
+	if length < current {
+		return fmt.Errorf("%w: %s.%s from %d to %d", ErrNarrowColumn, stmt.Table, field.DBName, current, length)
+	}
+	if length == current {
+		return nil
+	}
+	return m.DB.Exec(
+		"ALTER "+tableType.keyword()+" ? MODIFY "+kind+" ? ?",
+		clause.Table{Name: stmt.Table}, clause.Column{Name: field.DBName}, dataType,
+	).Error
+}
+
+// tableType looks the table up in SHOW STABLES, then in SHOW TABLES where subtables have a stable_name.
+func (m Migrator) tableType(name string) (TableType, error) {
+	row, err := m.showLike("STABLES", name)
+	if err != nil {
+		return 0, err
+	}
+	if row != nil {
+		return STable, nil
+	}
+	row, err = m.showLike("TABLES", name)
+	if err != nil || row == nil {
+		return 0, err
+	}
+	if stableName, _ := row["stable_name"].(string); stableName != "" {
+		return SubTable, nil
+	}
+	return NormalTable, nil
+}
+
+// alterableTableType returns the type of a table whose schema can be altered.
+func (m Migrator) alterableTableType(name string) (TableType, error) {
+	tableType, err := m.tableType(name)
+	if err != nil {
+		return 0, err
+	}
+	switch tableType {
+	case 0:
+		return 0, fmt.Errorf("%w: %s", ErrTableNotExist, name)
+	case SubTable:
+		return 0, fmt.Errorf("%w: %s", ErrSubTableSchema, name)
+	}
+	return tableType, nil
+}
+
+// showLike returns the row of SHOW STABLES or SHOW TABLES whose name is exactly name, nil if there is none.
+// A db.table name is looked up with SHOW db.STABLES, names in backticks are unquoted as the server returns them unquoted.
+func (m Migrator) showLike(what string, name string) (map[string]interface{}, error) {
+	parts := splitIdentifier(name)
+	name = parts[len(parts)-1]
+	query := m.DB.Raw("SHOW "+what+" LIKE ?", name)
+	if len(parts) == 2 {
+		query = m.DB.Raw("SHOW ?."+what+" LIKE ?", clause.Table{Name: parts[0]}, name)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range dest {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		if tableName, _ := values[0].(string); strings.EqualFold(tableName, name) {
+			return row, nil
+		}
+	}
+	return nil, rows.Err()
+}
+
+// describe returns the columns and tags of a table, tags are the rows noted as TAG.
+func (m Migrator) describe(name string) ([]Column, error) {
+	rows, err := m.DB.Raw("DESCRIBE ?", clause.Table{Name: name}).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) < 4 {
+		return nil, fmt.Errorf("DESCRIBE %s returns %d columns, expect Field, Type, Length and Note", name, len(names))
+	}
+	var columns []Column
+	for rows.Next() {
+		var (
+			column Column
+			length int64
+		)
+		// TDengine 3.x adds columns after Note, they are not used
+		dest := make([]interface{}, len(names))
+		dest[0], dest[1], dest[2], dest[3] = &column.name, &column.datatype, &length, &column.note
+		for i := 4; i < len(dest); i++ {
+			dest[i] = new(interface{})
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		column.maxlen = sql.NullInt64{Int64: length, Valid: true}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+func findColumn(columns []Column, name string) (Column, bool) {
+	for _, column := range columns {
+		if strings.EqualFold(column.name, name) {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// splitDataType splits "NCHAR(64)" into "NCHAR" and 64.
+func splitDataType(dataType string) (string, int64) {
+	dataType = strings.ToUpper(strings.TrimSpace(dataType))
+	i := strings.IndexByte(dataType, '(')
+	if i < 0 {
+		return dataType, 0
+	}
+	length, _ := strconv.ParseInt(strings.TrimSuffix(dataType[i+1:], ")"), 10, 64)
+	return dataType[:i], length
+}
+
+func lookUpField(stmt *gorm.Statement, name string) *schema.Field {
+	if stmt.Schema == nil {
+		return nil
+	}
+	return stmt.Schema.LookUpField(name)
+}
+
+func lookUpDBName(stmt *gorm.Statement, name string) string {
+	if field := lookUpField(stmt, name); field != nil {
+		return field.DBName
+	}
+	return name
+}
+
+func isTagField(field *schema.Field) bool {
+	_, ok := field.TagSettings["TAG"]
+	return ok
 }
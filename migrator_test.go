@@ -0,0 +1,199 @@
+package tdengine_gorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+type meter struct {
+	TS       time.Time
+	Current  float64
+	Voltage  int32
+	Location string `gorm:"tag;size:32"`
+	GroupID  int32  `gorm:"tag"`
+}
+
+var (
+	showColumns     = []string{"name", "created_time", "columns", "tags", "tables"}
+	describeColumns = []string{"Field", "Type", "Length", "Note"}
+)
+
+func TestAutoMigrateCreate(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SHOW STABLES LIKE 'meters'", showColumns)
+	d.Result("SHOW TABLES LIKE 'meters'", showColumns)
+	if err := db.Migrator().AutoMigrate(&meter{}); err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t, "CREATE STABLE IF NOT EXISTS meters (ts TIMESTAMP,current double,voltage int) TAGS (location NCHAR(32),group_id int)")
+}
+
+func TestAutoMigrateAlter(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SHOW STABLES LIKE 'meters'", showColumns, []driver.Value{"meters", time.Now(), int64(3), int64(1), int64(0)})
+	d.Result("DESCRIBE meters", describeColumns,
+		[]driver.Value{"ts", "TIMESTAMP", int64(8), ""},
+		[]driver.Value{"current", "DOUBLE", int64(8), ""},
+		[]driver.Value{"location", "NCHAR", int64(16), "TAG"},
+	)
+	if err := db.Migrator().AutoMigrate(&meter{}); err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"ALTER STABLE meters ADD COLUMN voltage int",
+		"ALTER STABLE meters MODIFY TAG location NCHAR(32)",
+		"ALTER STABLE meters ADD TAG group_id int",
+	)
+}
+
+func TestDescribeExtraColumns(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("DESCRIBE meters", []string{"field", "type", "length", "note", "encode", "compress", "level"},
+		[]driver.Value{"ts", "TIMESTAMP", int64(8), "", "delta-i", "lz4", "medium"},
+		[]driver.Value{"location", "NCHAR", int64(32), "TAG", "disabled", "disabled", "disabled"},
+	)
+	columns, err := db.Migrator().(Migrator).describe("meters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 2 || columns[1].name != "location" || columns[1].note != "TAG" || columns[1].maxlen.Int64 != 32 {
+		t.Errorf("unexpected columns %+v", columns)
+	}
+}
+
+func TestAutoMigrateUnsafe(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SHOW STABLES LIKE 'meters'", showColumns, []driver.Value{"meters", time.Now(), int64(3), int64(1), int64(0)})
+	d.Result("DESCRIBE meters", describeColumns,
+		[]driver.Value{"ts", "TIMESTAMP", int64(8), ""},
+		[]driver.Value{"current", "DOUBLE", int64(8), ""},
+		[]driver.Value{"voltage", "BIGINT", int64(8), ""},
+		[]driver.Value{"location", "NCHAR", int64(64), "TAG"},
+		[]driver.Value{"group_id", "INT", int64(4), "TAG"},
+	)
+	err := db.Migrator().AutoMigrate(&meter{})
+	if !errors.Is(err, ErrUnsafeMigration) {
+		t.Fatalf("expect ErrUnsafeMigration got %v", err)
+	}
+	d.AssertExecs(t)
+}
+
+func TestIntrospection(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SHOW STABLES LIKE 'meters'", showColumns, []driver.Value{"meters", time.Now(), int64(3), int64(1), int64(0)})
+	d.Result("SHOW STABLES LIKE 'd1001'", showColumns)
+	d.Result("SHOW TABLES LIKE 'd1001'", []string{"table_name", "created_time", "columns", "stable_name", "uid", "tid", "vgId"},
+		[]driver.Value{"d1001", time.Now(), int64(3), "meters", int64(1), int64(1), int64(2)},
+	)
+	d.Result("SHOW STABLES LIKE 'd1002'", showColumns)
+	d.Result("SHOW TABLES LIKE 'd1002'", showColumns)
+	for _, show := range []string{"SHOW TABLES LIKE 'd.1001'", "SHOW power.TABLES LIKE 'd.1001'"} {
+		d.Result(show, []string{"table_name", "created_time", "columns", "stable_name", "uid", "tid", "vgId"},
+			[]driver.Value{"d.1001", time.Now(), int64(3), "meters", int64(1), int64(1), int64(2)},
+		)
+	}
+	d.Result("SHOW STABLES LIKE 'd.1001'", showColumns)
+	d.Result("SHOW power.STABLES LIKE 'd.1001'", showColumns)
+	d.Result("DESCRIBE meters", describeColumns,
+		[]driver.Value{"ts", "TIMESTAMP", int64(8), ""},
+		[]driver.Value{"current", "DOUBLE", int64(8), ""},
+		[]driver.Value{"location", "NCHAR", int64(32), "TAG"},
+	)
+	migrator := db.Migrator()
+	if !migrator.HasTable(&meter{}) {
+		t.Error("expect meters exists")
+	}
+	if !migrator.HasTable("d1001") {
+		t.Error("expect d1001 exists")
+	}
+	if migrator.HasTable("d1002") {
+		t.Error("expect d1002 not exists")
+	}
+	for _, name := range []string{Identifier("d.1001"), "power.d.1001", "power.`d.1001`"} {
+		if tableType, err := migrator.(Migrator).TableType(name); err != nil || tableType != SubTable {
+			t.Errorf("expect %s is a subtable got %v %v", name, tableType, err)
+		}
+	}
+	if !migrator.HasColumn(&meter{}, "Location") {
+		t.Error("expect location exists")
+	}
+	if migrator.HasColumn(&meter{}, "Voltage") {
+		t.Error("expect voltage not exists")
+	}
+	columnTypes, err := migrator.ColumnTypes(&meter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columnTypes) != 3 {
+		t.Fatalf("expect 3 columns got %d", len(columnTypes))
+	}
+	location := columnTypes[2].(Column)
+	if length, _ := location.Length(); location.Name() != "location" || location.DatabaseTypeName() != "NCHAR" || length != 32 || !location.IsTag() {
+		t.Errorf("unexpected column %+v", location)
+	}
+	if columnTypes[1].(Column).IsTag() {
+		t.Error("expect current is not a tag")
+	}
+}
+
+func TestAlterColumn(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	tableColumns := []string{"table_name", "created_time", "columns", "stable_name", "uid", "tid", "vgId"}
+	d.Result("SHOW STABLES LIKE 'meters'", showColumns, []driver.Value{"meters", time.Now(), int64(3), int64(1), int64(0)})
+	d.Result("DESCRIBE meters", describeColumns,
+		[]driver.Value{"ts", "TIMESTAMP", int64(8), ""},
+		[]driver.Value{"current", "DOUBLE", int64(8), ""},
+		[]driver.Value{"location", "NCHAR", int64(64), "TAG"},
+		[]driver.Value{"group_id", "INT", int64(4), "TAG"},
+	)
+	d.Result("SHOW STABLES LIKE 'd1001'", showColumns)
+	d.Result("SHOW TABLES LIKE 'd1001'", tableColumns, []driver.Value{"d1001", time.Now(), int64(3), "meters", int64(1), int64(1), int64(2)})
+	d.Result("SHOW STABLES LIKE 'log'", showColumns)
+	d.Result("SHOW TABLES LIKE 'log'", tableColumns, []driver.Value{"log", time.Now(), int64(2), "", int64(1), int64(1), int64(2)})
+
+	migrator := db.Migrator()
+	if err := migrator.AddColumn(&meter{}, "Voltage"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.DropColumn(&meter{}, "GroupID"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.DropColumn(&meter{}, "current"); err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"ALTER STABLE meters ADD COLUMN voltage int",
+		"ALTER STABLE meters DROP TAG group_id",
+		"ALTER STABLE meters DROP COLUMN current",
+	)
+	if err := migrator.AlterColumn(&meter{}, "Location"); !errors.Is(err, ErrNarrowColumn) || !errors.Is(err, ErrUnsafeMigration) {
+		t.Errorf("expect ErrNarrowColumn got %v", err)
+	}
+	if err := migrator.DropColumn(&meter{}, "TS"); !errors.Is(err, ErrDropTimestamp) {
+		t.Errorf("expect ErrDropTimestamp got %v", err)
+	}
+	if err := db.Table("d1001").Migrator().AddColumn(&meter{}, "Voltage"); !errors.Is(err, ErrSubTableSchema) {
+		t.Errorf("expect ErrSubTableSchema got %v", err)
+	}
+	if err := db.Table("log").Migrator().AddColumn(&meter{}, "Location"); !errors.Is(err, ErrTagOnNormalTable) {
+		t.Errorf("expect ErrTagOnNormalTable got %v", err)
+	}
+	if tableType, err := migrator.(Migrator).TableType("log"); err != nil || tableType != NormalTable {
+		t.Errorf("expect NormalTable got %v %v", tableType, err)
+	}
+}
+
+func TestRenameColumn(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SHOW STABLES LIKE 'meters'", showColumns, []driver.Value{"meters", time.Now(), int64(3), int64(1), int64(0)})
+	migrator := db.Migrator()
+	if err := migrator.RenameColumn(&meter{}, "site", "Location"); err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t, "ALTER STABLE meters CHANGE TAG site location")
+	if err := migrator.RenameColumn(&meter{}, "amps", "Current"); !errors.Is(err, ErrRenameColumn) {
+		t.Errorf("expect ErrRenameColumn got %v", err)
+	}
+}
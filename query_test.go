@@ -0,0 +1,107 @@
+package tdengine_gorm
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/fill"
+	"github.com/taosdata/tdengine_gorm/clause/function"
+	"github.com/taosdata/tdengine_gorm/clause/interp"
+	"github.com/taosdata/tdengine_gorm/clause/partition"
+	"github.com/taosdata/tdengine_gorm/clause/pseudo"
+	"github.com/taosdata/tdengine_gorm/clause/slimit"
+	"github.com/taosdata/tdengine_gorm/clause/window"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestQueryClauses(t *testing.T) {
+	db, _ := openRecordDB(t, Dialect{})
+	db = db.Session(&gorm.Session{DryRun: true})
+	tests := []struct {
+		query  *gorm.DB
+		expect string
+	}{
+		{
+			db.Model(&meter{}).Select("avg(current)").
+				Clauses(
+					slimit.SetSLimit(2, 0),
+					fill.SetFill(fill.FillNull),
+					window.SetInterval(window.Duration{Value: 1, Unit: window.Minute}),
+					partition.SetPartition(partition.TBName),
+				).Where("voltage > ?", 200).Find(&[]map[string]interface{}{}),
+			"SELECT avg(current) FROM meters WHERE voltage > 200 PARTITION BY tbname INTERVAL(1m) FILL (NULL) SLIMIT 2",
+		},
+		{
+			db.Model(&meter{}).Select("location,max(voltage)").
+				Clauses(partition.SetPartition("location")).
+				Clauses(partition.SetPartition("group_id")).
+				Clauses(window.SetStateWindow("voltage")).Find(&[]map[string]interface{}{}),
+			"SELECT location,max(voltage) FROM meters PARTITION BY location,group_id STATE_WINDOW(voltage)",
+		},
+		{
+			db.Model(&meter{}).Select("avg(current),last(location),last(voltage)").
+				Clauses(
+					window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+					fill.SetFill(fill.FillValue).SetValues(0, "it's", false),
+				).Find(&[]map[string]interface{}{}),
+			`SELECT avg(current),last(location),last(voltage) FROM meters INTERVAL(10m) FILL (VALUE,0,'it\'s',0)`,
+		},
+		{
+			db.Model(&meter{}).
+				Clauses(function.Select(function.Percentile("current", 90).As("p90"), function.Apercentile("current", 50, function.AlgorithmTDigest))).
+				Find(&[]map[string]interface{}{}),
+			"SELECT PERCENTILE(current,90) AS p90,APERCENTILE(current,50,'t-digest') FROM meters",
+		},
+		{
+			db.Model(&meter{}).
+				Clauses(
+					fill.SetFill(fill.FillLinear),
+					interp.SetEvery(window.Duration{Value: 1, Unit: window.Second}),
+					interp.SetRange(time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC), time.Date(2021, 8, 11, 9, 44, 0, 0, time.UTC)),
+					partition.SetPartition(pseudo.TBName),
+					function.Select(pseudo.TBName, pseudo.IRowTS, function.Interp("current").As("current")),
+				).Where("location = ?", "SF").Find(&[]map[string]interface{}{}),
+			"SELECT tbname AS tb_name,_irowts AS interp_ts,INTERP(current) AS current FROM meters WHERE location = 'SF' PARTITION BY tbname " +
+				"RANGE('2021-08-11T09:43:00Z','2021-08-11T09:44:00Z') EVERY(1s) FILL (LINEAR)",
+		},
+	}
+	for _, test := range tests {
+		if sql := test.query.Statement.SQL.String(); sql != test.expect {
+			t.Errorf("expect %s got %s", test.expect, sql)
+		}
+		if test.query.Error != nil {
+			t.Error(test.query.Error)
+		}
+	}
+}
+
+func TestPseudoColumnScan(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{QuoteMode: QuoteNeeded})
+	start := time.Date(2021, 8, 11, 9, 40, 0, 0, time.UTC)
+	d.Result("SELECT _wstart AS window_start,tbname AS tb_name,avg(current) AS avg_current FROM meters WHERE tbname = 'd1001' PARTITION BY tbname INTERVAL(10m) ORDER BY _wstart",
+		[]string{"window_start", "tb_name", "avg_current"},
+		[]driver.Value{start, "d1001", 10.3},
+	)
+	var results []struct {
+		WindowStart time.Time
+		TBName      string
+		AvgCurrent  float64
+	}
+	err := db.Model(&meter{}).
+		Clauses(
+			pseudo.Select(pseudo.WStart, pseudo.TBName, "avg(current) AS avg_current"),
+			partition.SetPartition(pseudo.TBName),
+			window.SetInterval(window.Duration{Value: 10, Unit: window.Minute}),
+		).
+		Where(clause.Eq{Column: pseudo.TBName, Value: "d1001"}).
+		Order(clause.OrderByColumn{Column: pseudo.WStart}).
+		Find(&results).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].WindowStart.Equal(start) || results[0].TBName != "d1001" || results[0].AvgCurrent != 10.3 {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,154 @@
+package tdengine_gorm
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// QuoteMode selects the identifiers that QuoteTo wraps in backticks.
+type QuoteMode int
+
+const (
+	// QuoteNone writes identifiers as they are.
+	QuoteNone QuoteMode = iota
+	// QuoteNeeded quotes reserved words and identifiers that are not plain names, such as device IDs with dashes.
+	QuoteNeeded
+	// QuoteAll quotes every identifier.
+	QuoteAll
+)
+
+// reservedWords are the TDengine keywords that cannot be used as bare identifiers,
+// value and ts are included as they are keywords in some versions.
+var reservedWords = map[string]struct{}{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		ABORT ACCOUNT ACCOUNTS ADD AFTER AGGREGATE ALIVE ALL ALTER ANALYZE AND APPS AS ASC AT_ONCE ATTACH
+		BALANCE BEFORE BEGIN BETWEEN BIGINT BINARY BITAND BITNOT BITOR BLOCKS BNODE BNODES BOOL BOTH BUFFER BUFSIZE BY
+		CACHE CACHELAST CACHEMODEL CACHESIZE CASCADE CASE CAST CHANGE CLIENT_VERSION CLUSTER COLUMN COMMENT COMP COMPACT
+		CONCAT CONFLICT CONNECTION CONNECTIONS CONNS CONSUMER CONSUMERS CONTAINS COPY COUNT_WINDOW CREATE CTIME CURRENT_USER
+		DATABASE DATABASES DAYS DBS DEFERRED DELETE DELIMITERS DESC DESCRIBE DETACH DISTINCT DISTRIBUTED DIVIDE DNODE DNODES
+		DOUBLE DROP DURATION EACH ELSE ENABLE END EVENT_WINDOW EVERY EXISTS EXPIRED EXPLAIN EXPORT
+		FAIL FILE FILL FIRST FLOAT FLUSH FOR FROM FSYNC FULL FUNCTION FUNCTIONS GEOMETRY GLOB GRANT GRANTS GROUP
+		HAVING ID IF IGNORE IMMEDIATE IMPORT IN INDEX INDEXES INITIALLY INNER INSERT INSTEAD INT INTEGER INTERP INTERVAL INTO IS ISNULL
+		JOIN JSON KEEP KEY KILL LAST LAST_ROW LICENCES LIKE LIMIT LINEAR LOCAL
+		MATCH MAXROWS MAX_DELAY MERGE META MINROWS MINUS MNODE MNODES MODIFY MODULES
+		NCHAR NEXT NMATCH NONE NORMAL NOT NOTNULL NOW NULL NULL_F NULLS
+		OF OFFSET ON OR ORDER OUTPUTTYPE PAGES PAGESIZE PARTITION PASS PORT PPS PRECISION PREV PRIVILEGE
+		QNODE QNODES QTIME QUERIES QUERY QUORUM RANGE RATIO READ REDISTRIBUTE RENAME REPLACE REPLICA RESET RESTRICT RETENTIONS
+		REVOKE ROLLUP ROW SCHEMALESS SCORES SELECT SESSION SET SHOW SINGLE_STABLE SLIDING SLIMIT SMA SMALLINT SNODE SNODES SOFFSET
+		SPLIT STABLE STABLES STATE STATE_WINDOW STORAGE STREAM STREAMS STRICT STRING SUBSCRIPTIONS SYNCDB SYSINFO
+		TABLE TABLES TAG TAGS TBNAME THEN TIMES TIMESTAMP TIMEZONE TINYINT TO TODAY TOPIC TOPICS TRANSACTION TRANSACTIONS
+		TRIGGER TRIM TS TSERIES TTL UNION UNSAFE UNSIGNED UPDATE USE USER USERS USING
+		VALUE VALUES VALUE_F VARCHAR VARIABLE VARIABLES VERBOSE VGROUP VGROUPS VIEW VNODES WAL WATERMARK WHEN WHERE WINDOW_CLOSE WITH WRITE
+		_C0 _IROWTS _QEND _QSTART _ROWTS _WDURATION _WEND _WSTART
+	`) {
+		reservedWords[word] = struct{}{}
+	}
+}
+
+// IsReservedWord reports whether name is a TDengine keyword.
+func IsReservedWord(name string) bool {
+	_, ok := reservedWords[strings.ToUpper(name)]
+	return ok
+}
+
+// QuoteTo writes a table or column name, db.table names are quoted part by part.
+// Parts already in backticks are unquoted first so they are not quoted twice, embedded backticks are doubled.
+func (dialect Dialect) QuoteTo(writer clause.Writer, str string) {
+	if dialect.QuoteMode == QuoteNone {
+		writer.WriteString(str)
+		return
+	}
+	for i, part := range splitIdentifier(str) {
+		if i > 0 {
+			writer.WriteByte('.')
+		}
+		quoteIdentifier(writer, part, dialect.QuoteMode)
+	}
+}
+
+func quoteIdentifier(writer clause.Writer, name string, mode QuoteMode) {
+	if name == "*" || (mode == QuoteNeeded && !needsQuote(name)) {
+		writer.WriteString(name)
+		return
+	}
+	writer.WriteByte('`')
+	writer.WriteString(strings.ReplaceAll(name, "`", "``"))
+	writer.WriteByte('`')
+}
+
+// needsQuote reports whether name is a reserved word or not a plain [A-Za-z_][A-Za-z0-9_]* name.
+func needsQuote(name string) bool {
+	return IsReservedWord(name) || !isPlainName(name)
+}
+
+func isPlainName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Identifier returns name in backticks with embedded backticks doubled, QuoteTo writes it as one name
+// and does not split it on dots, for example a subtable named after the device ID d.1001.
+func Identifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// splitIdentifier splits db.table into the database and the table and unquotes the parts in backticks.
+// Database names are plain names, so the name is only split when the part before the first dot is a plain name
+// or in backticks, and the table keeps the dots after it: power.d.1001 is the table d.1001 in power
+// and d-1.2 is a single name. Backticks inside a part that does not start with one are part of the name.
+func splitIdentifier(str string) []string {
+	if name, rest, ok := cutQuoted(str); ok {
+		switch {
+		case rest == "":
+			return []string{name}
+		case rest[0] == '.':
+			return []string{name, unquoteIdentifier(rest[1:])}
+		}
+		return []string{str}
+	}
+	if i := strings.IndexByte(str, '.'); i > 0 && isPlainName(str[:i]) {
+		return []string{str[:i], unquoteIdentifier(str[i+1:])}
+	}
+	return []string{str}
+}
+
+// cutQuoted unquotes the name in backticks at the start of str and returns the rest of str after it.
+func cutQuoted(str string) (string, string, bool) {
+	if !strings.HasPrefix(str, "`") {
+		return "", str, false
+	}
+	var name strings.Builder
+	for i := 1; i < len(str); i++ {
+		if str[i] == '`' {
+			if i+1 < len(str) && str[i+1] == '`' {
+				name.WriteByte('`')
+				i++
+				continue
+			}
+			return name.String(), str[i+1:], true
+		}
+		name.WriteByte(str[i])
+	}
+	return "", str, false
+}
+
+func unquoteIdentifier(str string) string {
+	if name, rest, ok := cutQuoted(str); ok && rest == "" {
+		return name
+	}
+	return str
+}
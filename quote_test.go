@@ -0,0 +1,66 @@
+package tdengine_gorm
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/using"
+)
+
+func TestQuoteTo(t *testing.T) {
+	tests := []struct {
+		mode   QuoteMode
+		name   string
+		expect string
+	}{
+		{QuoteNone, "d-1001", "d-1001"},
+		{QuoteNone, "`d-1001`", "`d-1001`"},
+		{QuoteNeeded, "d1001", "d1001"},
+		{QuoteNeeded, "d-1001", "`d-1001`"},
+		{QuoteNeeded, "value", "`value`"},
+		{QuoteNeeded, "TS", "`TS`"},
+		{QuoteNeeded, "desc", "`desc`"},
+		{QuoteNeeded, "1d", "`1d`"},
+		{QuoteNeeded, "power.d-1001", "power.`d-1001`"},
+		{QuoteNeeded, "power.`d.1001`", "power.`d.1001`"},
+		{QuoteNeeded, "power.d.1001", "power.`d.1001`"},
+		{QuoteNeeded, "d-1.2.3", "`d-1.2.3`"},
+		{QuoteNeeded, Identifier("d.1001"), "`d.1001`"},
+		{QuoteNone, Identifier("d.1001"), "`d.1001`"},
+		{QuoteAll, Identifier("a`b.c"), "`a``b.c`"},
+		{QuoteNeeded, "*", "*"},
+		{QuoteAll, "power.meters", "`power`.`meters`"},
+		{QuoteAll, "a`b", "`a``b`"},
+		{QuoteAll, "`a``b`", "`a``b`"},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		Dialect{QuoteMode: test.mode}.QuoteTo(&b, test.name)
+		if b.String() != test.expect {
+			t.Errorf("%d %s: expect %s got %s", test.mode, test.name, test.expect, b.String())
+		}
+	}
+}
+
+func TestQuoteStatements(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{QuoteMode: QuoteNeeded})
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	err := db.Table("power.d-1001").Clauses(using.SetUsing("power.meters", map[string]interface{}{"desc": "SF"})).
+		Create(map[string]interface{}{"ts": ts, "value": 10.2}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Result("SHOW power.STABLES LIKE 'meters'", showColumns, []driver.Value{"meters", time.Now(), int64(3), int64(1), int64(0)})
+	if !db.Migrator().HasTable("power.meters") {
+		t.Error("expect power.meters exists")
+	}
+	if err := db.Table(Identifier("d.1001")).Create(map[string]interface{}{"ts": ts, "value": 10.3}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO power.`d-1001` USING power.meters(`desc`) TAGS('SF') (`ts`,`value`) VALUES ('2021-08-11T09:43:00Z',10.2)",
+		"INSERT INTO `d.1001` (`ts`,`value`) VALUES ('2021-08-11T09:43:00Z',10.3)",
+	)
+}
@@ -0,0 +1,428 @@
+package tdengine_gorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v2/common"
+	taosErrors "github.com/taosdata/driver-go/v2/errors"
+	taosTypes "github.com/taosdata/driver-go/v2/types"
+	"github.com/taosdata/driver-go/v2/wrapper"
+	"github.com/taosdata/tdengine_gorm/clause/using"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InsertMode selects how Create sends rows to TDengine.
+type InsertMode int
+
+const (
+	// InsertSQL interpolates the rows into INSERT statements, see Dialect.MaxSQLLength.
+	InsertSQL InsertMode = iota
+	// InsertStmt binds the rows to a prepared INSERT with taos_stmt, see Dialect.StmtConn.
+	InsertStmt
+)
+
+// DefaultMaxStmtRows is the default number of rows InsertStmt binds in one batch.
+const DefaultMaxStmtRows = 4096
+
+// ErrStmtConn is returned by Initialize when InsertStmt is selected without a StmtConn.
+var ErrStmtConn = errors.New("insert mode InsertStmt needs a StmtConn")
+
+// StmtConn executes prepared INSERT statements for InsertStmt.
+type StmtConn interface {
+	// InsertBatch prepares sql, sets the table name, and the tags when tags is not nil,
+	// then binds columns as one batch and executes it. columns[i][j] is row j of column i,
+	// values are driver-go types such as types.TaosDouble.
+	InsertBatch(sql string, table string, tags []interface{}, columns [][]interface{}) (int64, error)
+}
+
+// NativeStmtConn is a StmtConn on a native connection of its own, taos_stmt cannot use a database/sql connection.
+// The columns are bound with taos_stmt_bind_param_batch, client libraries without it return ErrBindParamBatch.
+type NativeStmtConn struct {
+	mu   sync.Mutex
+	taos unsafe.Pointer
+}
+
+// OpenStmtConn opens a native connection for InsertStmt.
+func OpenStmtConn(host, user, password, db string, port int) (*NativeStmtConn, error) {
+	taos, err := wrapper.TaosConnect(host, user, password, db, port)
+	if err != nil {
+		return nil, err
+	}
+	return &NativeStmtConn{taos: taos}, nil
+}
+
+func (c *NativeStmtConn) InsertBatch(sql string, table string, tags []interface{}, columns [][]interface{}) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stmt := wrapper.TaosStmtInit(c.taos)
+	if stmt == nil {
+		return 0, errors.New("taos_stmt_init failed")
+	}
+	defer wrapper.TaosStmtClose(stmt)
+	if code := wrapper.TaosStmtPrepare(stmt, sql); code != 0 {
+		return 0, taosErrors.GetError(code)
+	}
+	var code int
+	if tags != nil {
+		code = wrapper.TaosStmtSetTBNameTags(stmt, table, tags)
+	} else {
+		code = wrapper.TaosStmtSetTBName(stmt, table)
+	}
+	if code != 0 {
+		return 0, taosErrors.GetError(code)
+	}
+	rows := 0
+	if len(columns) > 0 {
+		rows = len(columns[0])
+	}
+	if rows == 0 {
+		return 0, nil
+	}
+	binds := make([]*multiBind, len(columns))
+	for i, column := range columns {
+		bind, err := newMultiBind(column)
+		if err != nil {
+			return 0, fmt.Errorf("bind column %d: %w", i, err)
+		}
+		binds[i] = bind
+	}
+	code, err := bindParamBatch(stmt, binds, rows)
+	if err != nil {
+		return 0, err
+	}
+	if code != 0 {
+		return 0, taosErrors.GetError(code)
+	}
+	if code := wrapper.TaosStmtAddBatch(stmt); code != 0 {
+		return 0, taosErrors.GetError(code)
+	}
+	if code := wrapper.TaosStmtExecute(stmt); code != 0 {
+		return 0, taosErrors.GetError(code)
+	}
+	return int64(rows), nil
+}
+
+// Close closes the native connection.
+func (c *NativeStmtConn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wrapper.TaosClose(c.taos)
+}
+
+// stmtCreate inserts values through conn in batches of at most Dialect.MaxStmtRows rows.
+func stmtCreate(db *gorm.DB, conn StmtConn, values clause.Values) {
+	stmt := db.Statement
+	maxRows := DefaultMaxStmtRows
+	if dialect, ok := stmt.Dialector.(Dialect); ok && dialect.MaxStmtRows > 0 {
+		maxRows = dialect.MaxStmtRows
+	}
+	chunks := (len(values.Values) + maxRows - 1) / maxRows
+	var rowsAffected int64
+	for i := 0; i < chunks; i++ {
+		first, last := i*maxRows, (i+1)*maxRows
+		if last > len(values.Values) {
+			last = len(values.Values)
+		}
+		stmt.SQL.Reset()
+		stmt.Vars = nil
+		n, err := stmtInsert(db, conn, clause.Values{Columns: values.Columns, Values: values.Values[first:last]})
+		if err != nil {
+			if chunks > 1 {
+				err = fmt.Errorf("insert chunk %d/%d (rows %d-%d): %w", i+1, chunks, first+1, last, err)
+			}
+			db.AddError(err)
+			break
+		}
+		rowsAffected += n
+	}
+	db.RowsAffected = rowsAffected
+}
+
+// stmtInsert inserts values through conn, a USING clause becomes the tags of the prepared statement.
+// Columns that are NULL in every row are left out, a batch of NULL values has no type to bind.
+func stmtInsert(db *gorm.DB, conn StmtConn, values clause.Values) (int64, error) {
+	stmt := db.Statement
+	values = withoutNullColumns(values)
+	columns := make([][]interface{}, len(values.Columns))
+	for i, column := range values.Columns {
+		columnValues := make([]interface{}, len(values.Values))
+		for j, row := range values.Values {
+			columnValues[j] = row[i]
+		}
+		bound, err := bindValues(stmt, column.Name, columnValues)
+		if err != nil {
+			return 0, err
+		}
+		columns[i] = bound
+	}
+
+	stmt.WriteString("INSERT INTO ? ")
+	var tags []interface{}
+	if c, ok := stmt.Clauses["USING"]; ok {
+		if u, ok := c.Expression.(using.Using); ok {
+			tags = []interface{}{}
+			stmt.WriteString("USING ")
+			stmt.WriteQuoted(u.STable())
+			stmt.WriteString(" (")
+			for i, pair := range u.TagPairs() {
+				if i > 0 {
+					stmt.WriteByte(',')
+				}
+				stmt.WriteQuoted(pair.Name)
+				bound, err := bindValues(stmt, pair.Name, []interface{}{pair.Value})
+				if err != nil {
+					return 0, err
+				}
+				tags = append(tags, bound[0])
+			}
+			stmt.WriteString(") TAGS (")
+			stmt.WriteString(strings.TrimSuffix(strings.Repeat("?,", len(u.TagPairs())), ","))
+			stmt.WriteString(") ")
+		}
+	}
+	stmt.WriteByte('(')
+	for i, column := range values.Columns {
+		if i > 0 {
+			stmt.WriteByte(',')
+		}
+		stmt.WriteQuoted(column.Name)
+	}
+	stmt.WriteString(") VALUES (")
+	stmt.WriteString(strings.TrimSuffix(strings.Repeat("?,", len(values.Columns)), ","))
+	stmt.WriteByte(')')
+	if db.DryRun {
+		return 0, nil
+	}
+	return conn.InsertBatch(stmt.SQL.String(), stmt.Table, tags, columns)
+}
+
+// withoutNullColumns removes the columns that are nil in every row.
+func withoutNullColumns(values clause.Values) clause.Values {
+	var kept []int
+	for i := range values.Columns {
+		for _, row := range values.Values {
+			if v, err := indirectValue(row[i]); err != nil || v != nil {
+				kept = append(kept, i)
+				break
+			}
+		}
+	}
+	if len(kept) == len(values.Columns) {
+		return values
+	}
+	result := clause.Values{Columns: make([]clause.Column, len(kept)), Values: make([][]interface{}, len(values.Values))}
+	for j, i := range kept {
+		result.Columns[j] = values.Columns[i]
+	}
+	for r, row := range values.Values {
+		result.Values[r] = make([]interface{}, len(kept))
+		for j, i := range kept {
+			result.Values[r][j] = row[i]
+		}
+	}
+	return result
+}
+
+// bindValues converts the values of a column or tag to driver-go types.
+// The type is taken from the schema field when there is one, otherwise from the Go type of the values.
+func bindValues(stmt *gorm.Statement, name string, values []interface{}) ([]interface{}, error) {
+	var dataType string
+	precision := common.PrecisionMilliSecond
+	if dialect, ok := stmt.Dialector.(Dialect); ok {
+		precision = dialect.precision()
+	}
+	if field := lookUpField(stmt, name); field != nil {
+		dataType = stmt.Dialector.DataTypeOf(field)
+	}
+	bound := make([]interface{}, len(values))
+	for i, v := range values {
+		v, err := indirectValue(v)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		if dataType == "" {
+			dataType = bindTypeOf(v)
+		}
+		if bound[i], err = bindValue(v, dataType, precision); err != nil {
+			return nil, fmt.Errorf("bind %s: %w", name, err)
+		}
+	}
+	return bound, nil
+}
+
+// indirectValue resolves driver.Valuer and pointers, nil pointers become nil.
+func indirectValue(v interface{}) (interface{}, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		v = value
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	return rv.Interface(), nil
+}
+
+// bindTypeOf the TDengine type of a Go value, the same mapping as DataTypeOf.
+func bindTypeOf(v interface{}) string {
+	switch v.(type) {
+	case time.Time:
+		return "TIMESTAMP"
+	case []byte:
+		return "BINARY"
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int8, reflect.Uint8:
+		return "tinyint"
+	case reflect.Int16, reflect.Uint16:
+		return "smallint"
+	case reflect.Int32, reflect.Uint32:
+		return "int"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "bigint"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		return "NCHAR"
+	}
+	return ""
+}
+
+// bindValue converts v to the driver-go type of a TDengine data type such as NCHAR(64).
+func bindValue(v interface{}, dataType string, precision int) (interface{}, error) {
+	dataType, _ = splitDataType(dataType)
+	dataType = strings.Join(strings.Fields(dataType), " ")
+	rv := reflect.ValueOf(v)
+	switch dataType {
+	case "BOOL":
+		if rv.Kind() == reflect.Bool {
+			return taosTypes.TaosBool(rv.Bool()), nil
+		}
+	case "TINYINT", "SMALLINT", "INT", "BIGINT":
+		i, err := bindInt(rv, dataType, intBits[dataType])
+		if err != nil {
+			return nil, err
+		}
+		switch dataType {
+		case "TINYINT":
+			return taosTypes.TaosTinyint(i), nil
+		case "SMALLINT":
+			return taosTypes.TaosSmallint(i), nil
+		case "INT":
+			return taosTypes.TaosInt(i), nil
+		}
+		return taosTypes.TaosBigint(i), nil
+	case "TINYINT UNSIGNED", "SMALLINT UNSIGNED", "INT UNSIGNED", "BIGINT UNSIGNED":
+		u, err := bindUint(rv, dataType, intBits[strings.TrimSuffix(dataType, " UNSIGNED")])
+		if err != nil {
+			return nil, err
+		}
+		switch dataType {
+		case "TINYINT UNSIGNED":
+			return taosTypes.TaosUTinyint(u), nil
+		case "SMALLINT UNSIGNED":
+			return taosTypes.TaosUSmallint(u), nil
+		case "INT UNSIGNED":
+			return taosTypes.TaosUInt(u), nil
+		}
+		return taosTypes.TaosUBigint(u), nil
+	case "FLOAT", "DOUBLE":
+		var f float64
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = rv.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(rv.Uint())
+		default:
+			return nil, fmt.Errorf("unsupported %T for %s", v, dataType)
+		}
+		if dataType == "FLOAT" {
+			return taosTypes.TaosFloat(f), nil
+		}
+		return taosTypes.TaosDouble(f), nil
+	case "BINARY":
+		switch value := v.(type) {
+		case []byte:
+			return taosTypes.TaosBinary(value), nil
+		case string:
+			return taosTypes.TaosBinary(value), nil
+		}
+	case "NCHAR":
+		switch value := v.(type) {
+		case string:
+			return taosTypes.TaosNchar(value), nil
+		case []byte:
+			return taosTypes.TaosNchar(value), nil
+		}
+	case "TIMESTAMP":
+		if t, ok := v.(time.Time); ok {
+			return taosTypes.TaosTimestamp{T: t, Precision: precision}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported %T for %s", v, dataType)
+}
+
+// intBits are the widths of the TDengine integer types.
+var intBits = map[string]uint{"TINYINT": 8, "SMALLINT": 16, "INT": 32, "BIGINT": 64}
+
+// bindInt returns the integer rv when it is in the range of a signed integer type of bits.
+func bindInt(rv reflect.Value, dataType string, bits uint) (int64, error) {
+	max := int64(1)<<(bits-1) - 1
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := rv.Int(); i >= -max-1 && i <= max {
+			return i, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= uint64(max) {
+			return int64(u), nil
+		}
+	default:
+		return 0, fmt.Errorf("unsupported %s for %s", rv.Type(), dataType)
+	}
+	return 0, fmt.Errorf("%v is out of range for %s", rv.Interface(), dataType)
+}
+
+// bindUint returns the integer rv when it is in the range of an unsigned integer type of bits.
+func bindUint(rv reflect.Value, dataType string, bits uint) (uint64, error) {
+	max := uint64(1)<<(bits-1)<<1 - 1
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := rv.Int(); i >= 0 && uint64(i) <= max {
+			return uint64(i), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= max {
+			return u, nil
+		}
+	default:
+		return 0, fmt.Errorf("unsupported %s for %s", rv.Type(), dataType)
+	}
+	return 0, fmt.Errorf("%v is out of range for %s", rv.Interface(), dataType)
+}
@@ -0,0 +1,240 @@
+package tdengine_gorm
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// TAOS_MULTI_BIND of taos.h, the header is not included as older client libraries do not declare it
+typedef struct {
+	int       buffer_type;
+	void     *buffer;
+	uintptr_t buffer_length;
+	int32_t  *length;
+	char     *is_null;
+	int       num;
+} gorm_multi_bind;
+
+extern int taos_stmt_bind_param_batch(void *stmt, gorm_multi_bind *bind) __attribute__((weak));
+
+static int gorm_has_bind_param_batch() {
+	return taos_stmt_bind_param_batch != NULL;
+}
+
+static int gorm_stmt_bind_param_batch(void *stmt, gorm_multi_bind *bind) {
+	return taos_stmt_bind_param_batch(stmt, bind);
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v2/common"
+	taosTypes "github.com/taosdata/driver-go/v2/types"
+)
+
+// the TSDB_DATA_TYPE codes of taos.h
+const (
+	typeBool      = 1
+	typeTinyint   = 2
+	typeSmallint  = 3
+	typeInt       = 4
+	typeBigint    = 5
+	typeFloat     = 6
+	typeDouble    = 7
+	typeBinary    = 8
+	typeTimestamp = 9
+	typeNchar     = 10
+	typeUTinyint  = 11
+	typeUSmallint = 12
+	typeUInt      = 13
+	typeUBigint   = 14
+)
+
+// ErrBindParamBatch is returned by NativeStmtConn when the client library has no taos_stmt_bind_param_batch.
+var ErrBindParamBatch = errors.New("taos_stmt_bind_param_batch not found in the client library")
+
+// multiBind is a column in the layout of TAOS_MULTI_BIND, the values are bufferLength bytes each in buffer,
+// lengths are the byte lengths of BINARY and NCHAR values and isNull is 1 for NULL.
+type multiBind struct {
+	bufferType   int
+	buffer       []byte
+	bufferLength int
+	lengths      []int32
+	isNull       []byte
+}
+
+// newMultiBind lays out the driver-go values of a column, the type is the type of the first value that is not nil.
+func newMultiBind(values []interface{}) (*multiBind, error) {
+	b := &multiBind{isNull: make([]byte, len(values))}
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		switch value := v.(type) {
+		case taosTypes.TaosBool:
+			b.bufferType, b.bufferLength = typeBool, 1
+		case taosTypes.TaosTinyint:
+			b.bufferType, b.bufferLength = typeTinyint, 1
+		case taosTypes.TaosSmallint:
+			b.bufferType, b.bufferLength = typeSmallint, 2
+		case taosTypes.TaosInt:
+			b.bufferType, b.bufferLength = typeInt, 4
+		case taosTypes.TaosBigint:
+			b.bufferType, b.bufferLength = typeBigint, 8
+		case taosTypes.TaosUTinyint:
+			b.bufferType, b.bufferLength = typeUTinyint, 1
+		case taosTypes.TaosUSmallint:
+			b.bufferType, b.bufferLength = typeUSmallint, 2
+		case taosTypes.TaosUInt:
+			b.bufferType, b.bufferLength = typeUInt, 4
+		case taosTypes.TaosUBigint:
+			b.bufferType, b.bufferLength = typeUBigint, 8
+		case taosTypes.TaosFloat:
+			b.bufferType, b.bufferLength = typeFloat, 4
+		case taosTypes.TaosDouble:
+			b.bufferType, b.bufferLength = typeDouble, 8
+		case taosTypes.TaosTimestamp:
+			b.bufferType, b.bufferLength = typeTimestamp, 8
+		case taosTypes.TaosBinary:
+			b.bufferType = typeBinary
+			b.lengths = make([]int32, len(values))
+		case taosTypes.TaosNchar:
+			b.bufferType = typeNchar
+			b.lengths = make([]int32, len(values))
+		default:
+			return nil, fmt.Errorf("unsupported bind type %T", value)
+		}
+		break
+	}
+	if b.bufferType == 0 {
+		return nil, errors.New("a column of NULL values has no bind type")
+	}
+	if b.lengths != nil {
+		// BINARY and NCHAR values are padded to the longest value
+		for _, v := range values {
+			switch value := v.(type) {
+			case taosTypes.TaosBinary:
+				if len(value) > b.bufferLength {
+					b.bufferLength = len(value)
+				}
+			case taosTypes.TaosNchar:
+				if len(value) > b.bufferLength {
+					b.bufferLength = len(value)
+				}
+			}
+		}
+		if b.bufferLength == 0 {
+			b.bufferLength = 1
+		}
+	}
+	b.buffer = make([]byte, len(values)*b.bufferLength)
+	for i, v := range values {
+		if v == nil {
+			b.isNull[i] = 1
+			continue
+		}
+		p := unsafe.Pointer(&b.buffer[i*b.bufferLength])
+		ok := true
+		switch b.bufferType {
+		case typeBool:
+			var value taosTypes.TaosBool
+			if value, ok = v.(taosTypes.TaosBool); value {
+				*(*int8)(p) = 1
+			}
+		case typeTinyint:
+			var value taosTypes.TaosTinyint
+			value, ok = v.(taosTypes.TaosTinyint)
+			*(*int8)(p) = int8(value)
+		case typeSmallint:
+			var value taosTypes.TaosSmallint
+			value, ok = v.(taosTypes.TaosSmallint)
+			*(*int16)(p) = int16(value)
+		case typeInt:
+			var value taosTypes.TaosInt
+			value, ok = v.(taosTypes.TaosInt)
+			*(*int32)(p) = int32(value)
+		case typeBigint:
+			var value taosTypes.TaosBigint
+			value, ok = v.(taosTypes.TaosBigint)
+			*(*int64)(p) = int64(value)
+		case typeUTinyint:
+			var value taosTypes.TaosUTinyint
+			value, ok = v.(taosTypes.TaosUTinyint)
+			*(*uint8)(p) = uint8(value)
+		case typeUSmallint:
+			var value taosTypes.TaosUSmallint
+			value, ok = v.(taosTypes.TaosUSmallint)
+			*(*uint16)(p) = uint16(value)
+		case typeUInt:
+			var value taosTypes.TaosUInt
+			value, ok = v.(taosTypes.TaosUInt)
+			*(*uint32)(p) = uint32(value)
+		case typeUBigint:
+			var value taosTypes.TaosUBigint
+			value, ok = v.(taosTypes.TaosUBigint)
+			*(*uint64)(p) = uint64(value)
+		case typeFloat:
+			var value taosTypes.TaosFloat
+			value, ok = v.(taosTypes.TaosFloat)
+			*(*float32)(p) = float32(value)
+		case typeDouble:
+			var value taosTypes.TaosDouble
+			value, ok = v.(taosTypes.TaosDouble)
+			*(*float64)(p) = float64(value)
+		case typeTimestamp:
+			var value taosTypes.TaosTimestamp
+			if value, ok = v.(taosTypes.TaosTimestamp); ok {
+				*(*int64)(p) = common.TimeToTimestamp(value.T, value.Precision)
+			}
+		case typeBinary:
+			var value taosTypes.TaosBinary
+			value, ok = v.(taosTypes.TaosBinary)
+			b.lengths[i] = int32(copy(b.buffer[i*b.bufferLength:], value))
+		case typeNchar:
+			var value taosTypes.TaosNchar
+			value, ok = v.(taosTypes.TaosNchar)
+			b.lengths[i] = int32(copy(b.buffer[i*b.bufferLength:], value))
+		}
+		if !ok {
+			return nil, fmt.Errorf("row %d is %T in a column of bind type %d", i+1, v, b.bufferType)
+		}
+	}
+	return b, nil
+}
+
+// bindParamBatch binds the columns to stmt with taos_stmt_bind_param_batch,
+// the buffers are copied to C memory that is freed when the call returns.
+func bindParamBatch(stmt unsafe.Pointer, columns []*multiBind, rows int) (int, error) {
+	if C.gorm_has_bind_param_batch() == 0 {
+		return 0, ErrBindParamBatch
+	}
+	if len(columns) == 0 {
+		return 0, nil
+	}
+	binds := (*[1 << 20]C.gorm_multi_bind)(C.calloc(C.size_t(len(columns)), C.size_t(unsafe.Sizeof(C.gorm_multi_bind{}))))[:len(columns):len(columns)]
+	var pointers []unsafe.Pointer
+	defer func() {
+		for _, p := range pointers {
+			C.free(p)
+		}
+		C.free(unsafe.Pointer(&binds[0]))
+	}()
+	for i, column := range columns {
+		bind := &binds[i]
+		bind.buffer_type = C.int(column.bufferType)
+		bind.buffer = C.CBytes(column.buffer)
+		pointers = append(pointers, bind.buffer)
+		bind.buffer_length = C.uintptr_t(column.bufferLength)
+		bind.is_null = (*C.char)(C.CBytes(column.isNull))
+		pointers = append(pointers, unsafe.Pointer(bind.is_null))
+		if column.lengths != nil {
+			lengths := C.CBytes((*[1 << 28]byte)(unsafe.Pointer(&column.lengths[0]))[: 4*len(column.lengths) : 4*len(column.lengths)])
+			bind.length = (*C.int32_t)(lengths)
+			pointers = append(pointers, lengths)
+		}
+		bind.num = C.int(rows)
+	}
+	return int(C.gorm_stmt_bind_param_batch(stmt, &binds[0])), nil
+}
@@ -0,0 +1,185 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v2/common"
+	taosTypes "github.com/taosdata/driver-go/v2/types"
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"github.com/taosdata/tdengine_gorm/clause/using"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordStmtConn is a StmtConn that records the batches instead of sending them.
+type recordStmtConn struct {
+	sql     string
+	table   string
+	tags    []interface{}
+	columns [][]interface{}
+	batches int
+}
+
+func (c *recordStmtConn) InsertBatch(sql string, table string, tags []interface{}, columns [][]interface{}) (int64, error) {
+	c.sql, c.table, c.tags, c.columns = sql, table, tags, columns
+	c.batches++
+	return int64(len(columns[0])), nil
+}
+
+func TestStmtCreate(t *testing.T) {
+	conn := &recordStmtConn{}
+	db, d := openRecordDB(t, Dialect{InsertMode: InsertStmt, StmtConn: conn})
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	result := db.Clauses(using.SetUsing("meters", map[string]interface{}{"location": "SF", "group_id": int32(2)})).
+		Create(&[]reading{{TS: ts, Value: 1}, {TS: ts.Add(time.Second), Value: 2}})
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.RowsAffected != 2 {
+		t.Errorf("expect 2 rows affected got %d", result.RowsAffected)
+	}
+	d.AssertExecs(t)
+	if expect := "INSERT INTO ? USING meters (group_id,location) TAGS (?,?) (ts,value) VALUES (?,?)"; conn.sql != expect {
+		t.Errorf("expect %s got %s", expect, conn.sql)
+	}
+	if conn.table != "d1001" {
+		t.Errorf("expect d1001 got %s", conn.table)
+	}
+	if expect := []interface{}{taosTypes.TaosInt(2), taosTypes.TaosNchar("SF")}; !reflect.DeepEqual(conn.tags, expect) {
+		t.Errorf("expect tags %v got %v", expect, conn.tags)
+	}
+	expect := [][]interface{}{
+		{
+			taosTypes.TaosTimestamp{T: ts, Precision: common.PrecisionMilliSecond},
+			taosTypes.TaosTimestamp{T: ts.Add(time.Second), Precision: common.PrecisionMilliSecond},
+		},
+		{taosTypes.TaosBigint(1), taosTypes.TaosBigint(2)},
+	}
+	if !reflect.DeepEqual(conn.columns, expect) {
+		t.Errorf("expect columns %v got %v", expect, conn.columns)
+	}
+
+	if err := db.Table("d1002").Create(map[string]interface{}{"ts": ts, "current": "high"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if conn.sql != "INSERT INTO ? (current,ts) VALUES (?,?)" || conn.tags != nil {
+		t.Errorf("unexpected statement %s tags %v", conn.sql, conn.tags)
+	}
+	if err := db.Create(&meter{TS: ts, Voltage: 220}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(conn.columns[2], []interface{}{taosTypes.TaosInt(220)}) {
+		t.Errorf("expect voltage bound as int got %v", conn.columns[2])
+	}
+
+	db, _ = openRecordDB(t, Dialect{InsertMode: InsertStmt, StmtConn: conn, Precision: database.PrecisionNanosecond})
+	if err := db.Create(&reading{TS: ts}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if expect := (taosTypes.TaosTimestamp{T: ts, Precision: common.PrecisionNanoSecond}); conn.columns[0][0] != expect {
+		t.Errorf("expect %v got %v", expect, conn.columns[0][0])
+	}
+
+	// rows are bound in batches of MaxStmtRows
+	conn = &recordStmtConn{}
+	db, _ = openRecordDB(t, Dialect{InsertMode: InsertStmt, StmtConn: conn, MaxStmtRows: 2})
+	result = db.Create(&[]reading{{TS: ts, Value: 1}, {TS: ts.Add(time.Second), Value: 2}, {TS: ts.Add(2 * time.Second), Value: 3}})
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.RowsAffected != 3 || conn.batches != 2 {
+		t.Errorf("expect 3 rows in 2 batches got %d rows in %d batches", result.RowsAffected, conn.batches)
+	}
+	if !reflect.DeepEqual(conn.columns[1], []interface{}{taosTypes.TaosBigint(3)}) {
+		t.Errorf("expect the last row in the last batch got %v", conn.columns[1])
+	}
+}
+
+func TestStmtBindErrors(t *testing.T) {
+	if _, err := bindValue("high", "double", common.PrecisionMilliSecond); err == nil {
+		t.Error("expect string for double to fail")
+	}
+	if v, err := bindValue(int64(3), "FLOAT", common.PrecisionMilliSecond); err != nil || v != taosTypes.TaosFloat(3) {
+		t.Errorf("expect TaosFloat(3) got %v %v", v, err)
+	}
+	for _, test := range []struct {
+		value    interface{}
+		dataType string
+	}{
+		{uint8(200), "TINYINT"},
+		{int16(-129), "TINYINT"},
+		{int32(1 << 16), "SMALLINT"},
+		{uint32(1 << 31), "INT"},
+		{uint64(1 << 63), "BIGINT"},
+		{-1, "TINYINT UNSIGNED"},
+		{256, "TINYINT UNSIGNED"},
+		{int64(1 << 32), "INT UNSIGNED"},
+	} {
+		if v, err := bindValue(test.value, test.dataType, common.PrecisionMilliSecond); err == nil {
+			t.Errorf("expect %v for %s to be out of range got %v", test.value, test.dataType, v)
+		}
+	}
+	for _, test := range []struct {
+		value    interface{}
+		dataType string
+		expect   interface{}
+	}{
+		{int8(-128), "TINYINT", taosTypes.TaosTinyint(-128)},
+		{uint16(32767), "SMALLINT", taosTypes.TaosSmallint(32767)},
+		{uint8(200), "TINYINT UNSIGNED", taosTypes.TaosUTinyint(200)},
+		{uint64(1 << 63), "bigint  unsigned", taosTypes.TaosUBigint(1 << 63)},
+		{int32(65535), "SMALLINT UNSIGNED", taosTypes.TaosUSmallint(65535)},
+	} {
+		if v, err := bindValue(test.value, test.dataType, common.PrecisionMilliSecond); err != nil || v != test.expect {
+			t.Errorf("expect %v for %s got %v %v", test.expect, test.dataType, v, err)
+		}
+	}
+	db, _ := openRecordDB(t, Dialect{InsertMode: InsertStmt, StmtConn: &recordStmtConn{}})
+	if err := db.Create(&reading{TS: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&reading{}).Create(map[string]interface{}{"ts": "now"}).Error; err == nil {
+		t.Error("expect binding a string to a timestamp to fail")
+	}
+	if _, err := gorm.Open(Dialect{InsertMode: InsertStmt}, &gorm.Config{Logger: logger.Discard}); !errors.Is(err, ErrStmtConn) {
+		t.Errorf("expect ErrStmtConn got %v", err)
+	}
+}
+
+func TestStmtMultiBind(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	b, err := newMultiBind([]interface{}{taosTypes.TaosTimestamp{T: ts, Precision: common.PrecisionMilliSecond}, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.bufferType != typeTimestamp || b.bufferLength != 8 || len(b.buffer) != 16 || !reflect.DeepEqual(b.isNull, []byte{0, 1}) {
+		t.Errorf("unexpected timestamp column %+v", b)
+	}
+	if got := *(*int64)(unsafe.Pointer(&b.buffer[0])); got != 1628674980000 {
+		t.Errorf("expect 1628674980000 got %d", got)
+	}
+	b, err = newMultiBind([]interface{}{taosTypes.TaosNchar("SF"), nil, taosTypes.TaosNchar("LA-1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.bufferType != typeNchar || b.bufferLength != 4 || string(b.buffer) != "SF\x00\x00\x00\x00\x00\x00LA-1" || !reflect.DeepEqual(b.lengths, []int32{2, 0, 4}) {
+		t.Errorf("unexpected nchar column %+v", b)
+	}
+	if _, err = newMultiBind([]interface{}{taosTypes.TaosInt(1), taosTypes.TaosBigint(2)}); err == nil {
+		t.Error("expect mixed types to fail")
+	}
+
+	conn := &recordStmtConn{}
+	db, _ := openRecordDB(t, Dialect{InsertMode: InsertStmt, StmtConn: conn})
+	rows := []map[string]interface{}{{"ts": ts, "current": nil, "voltage": 220}, {"ts": ts.Add(time.Second), "current": nil, "voltage": nil}}
+	if err := db.Table("d1001").Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if expect := "INSERT INTO ? (ts,voltage) VALUES (?,?)"; conn.sql != expect {
+		t.Errorf("expect the NULL current column left out of %s got %s", expect, conn.sql)
+	}
+}
@@ -0,0 +1,117 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/taosdata/tdengine_gorm/clause/using"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubTableModel is implemented by models whose rows belong to a subtable of a supertable.
+// Create inserts them into SubTableName with USING STableName TAGS (...), so the subtable is created by its first row.
+// The tags are the TagValues of a TagValuer, otherwise the values of the tag fields of the model.
+type SubTableModel interface {
+	STableName() string
+	SubTableName() string
+}
+
+// TagValuer is implemented by a SubTableModel whose tags are not tag fields of the model.
+type TagValuer interface {
+	TagValues() map[string]interface{}
+}
+
+// ErrSubTableModel is returned by Create for a SubTableModel without a subtable name or tags
+// or with rows of one subtable that have different tags.
+var ErrSubTableModel = errors.New("invalid subtable model")
+
+type subTableRows struct {
+	table  string
+	using  using.Using
+	values clause.Values
+}
+
+// subTableGroups groups the created rows by subtable in the order the subtables first appear,
+// it returns nil when a created value does not implement SubTableModel.
+func subTableGroups(stmt *gorm.Statement, values clause.Values) ([]subTableRows, error) {
+	var rows []reflect.Value
+	switch rv := stmt.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, rv.Index(i))
+		}
+	case reflect.Struct:
+		rows = append(rows, rv)
+	}
+	if len(rows) == 0 || len(rows) != len(values.Values) {
+		return nil, nil
+	}
+	var (
+		groups []subTableRows
+		index  = map[string]int{}
+	)
+	for i, row := range rows {
+		subTable, ok := subTableOf(row)
+		if !ok {
+			return nil, nil
+		}
+		name := subTable.SubTableName()
+		if name == "" {
+			return nil, fmt.Errorf("%w: row %d has no subtable name", ErrSubTableModel, i+1)
+		}
+		tags := tagValuesOf(stmt, row, subTable)
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("%w: row %d of %s has no TagValues and no tag fields", ErrSubTableModel, i+1, name)
+		}
+		u := using.SetUsing(subTable.STableName(), tags)
+		g, ok := index[name]
+		if !ok {
+			g = len(groups)
+			index[name] = g
+			groups = append(groups, subTableRows{
+				table:  name,
+				using:  u,
+				values: clause.Values{Columns: values.Columns},
+			})
+		} else if first := groups[g].using; first.STable() != u.STable() || !reflect.DeepEqual(first.TagPairs(), u.TagPairs()) {
+			return nil, fmt.Errorf("%w: row %d of %s has the tags %s %v, an earlier row has %s %v",
+				ErrSubTableModel, i+1, name, u.STable(), u.TagPairs(), first.STable(), first.TagPairs())
+		}
+		groups[g].values.Values = append(groups[g].values.Values, values.Values[i])
+	}
+	return groups, nil
+}
+
+func subTableOf(row reflect.Value) (SubTableModel, bool) {
+	if row.Kind() == reflect.Ptr && row.IsNil() {
+		return nil, false
+	}
+	if subTable, ok := row.Interface().(SubTableModel); ok {
+		return subTable, true
+	}
+	if row.CanAddr() {
+		subTable, ok := row.Addr().Interface().(SubTableModel)
+		return subTable, ok
+	}
+	return nil, false
+}
+
+// tagValuesOf returns the TagValues of a TagValuer, otherwise the values of the tag fields of row by column name.
+func tagValuesOf(stmt *gorm.Statement, row reflect.Value, subTable SubTableModel) map[string]interface{} {
+	if valuer, ok := subTable.(TagValuer); ok {
+		return valuer.TagValues()
+	}
+	tags := map[string]interface{}{}
+	if stmt.Schema == nil {
+		return tags
+	}
+	row = reflect.Indirect(row)
+	for _, field := range stmt.Schema.Fields {
+		if isTagField(field) {
+			tags[field.DBName], _ = field.ValueOf(row)
+		}
+	}
+	return tags
+}
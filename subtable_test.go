@@ -0,0 +1,119 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type deviceReading struct {
+	TS       time.Time
+	Current  float64
+	Device   string `gorm:"-"`
+	Location string `gorm:"-"`
+}
+
+func (deviceReading) TableName() string {
+	return "meters"
+}
+
+func (r deviceReading) STableName() string {
+	return "meters"
+}
+
+func (r deviceReading) SubTableName() string {
+	return r.Device
+}
+
+func (r deviceReading) TagValues() map[string]interface{} {
+	return map[string]interface{}{"location": r.Location}
+}
+
+// taggedReading takes the tags of its subtable from its tag fields.
+type taggedReading struct {
+	TS       time.Time
+	Current  float64
+	Device   string `gorm:"-"`
+	Location string `gorm:"tag"`
+}
+
+func (taggedReading) TableName() string {
+	return "meters"
+}
+
+func (r taggedReading) STableName() string {
+	return "meters"
+}
+
+func (r taggedReading) SubTableName() string {
+	return r.Device
+}
+
+func TestSubTableCreate(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	db, d := openRecordDB(t, Dialect{})
+	if err := db.Create(&deviceReading{TS: ts, Current: 10.2, Device: "d1001", Location: "SF"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t, "INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2)")
+
+	db, d = openRecordDB(t, Dialect{})
+	result := db.Create([]*deviceReading{
+		{TS: ts, Current: 10.2, Device: "d1001", Location: "SF"},
+		{TS: ts, Current: 11.5, Device: "d1002", Location: "LA"},
+		{TS: ts.Add(time.Second), Current: 10.3, Device: "d1001", Location: "SF"},
+	})
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.RowsAffected != 3 {
+		t.Errorf("expect 3 rows affected got %d", result.RowsAffected)
+	}
+	if result.Statement.Table != "meters" {
+		t.Errorf("expect the table of the statement restored to meters got %s", result.Statement.Table)
+	}
+	if _, ok := result.Statement.Clauses["USING"]; ok {
+		t.Error("expect the USING clause of the last subtable removed")
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2),('2021-08-11T09:43:01Z',10.3)",
+		"INSERT INTO d1002 USING meters(location) TAGS('LA') (ts,current) VALUES ('2021-08-11T09:43:00Z',11.5)",
+	)
+
+	db, d = openRecordDB(t, Dialect{})
+	if err := db.Create(&[]taggedReading{{TS: ts, Current: 10.2, Device: "d1001", Location: "SF"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t, "INSERT INTO d1001 USING meters(location) TAGS('SF') (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2)")
+
+	conn := &recordStmtConn{}
+	db, _ = openRecordDB(t, Dialect{InsertMode: InsertStmt, StmtConn: conn})
+	if err := db.Create(&[]deviceReading{{TS: ts, Current: 10.2, Device: "d1003", Location: "SF"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if conn.table != "d1003" || conn.sql != "INSERT INTO ? USING meters (location) TAGS (?) (ts,current) VALUES (?,?)" {
+		t.Errorf("unexpected statement %s for %s", conn.sql, conn.table)
+	}
+}
+
+func TestSubTableErrors(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	db, d := openRecordDB(t, Dialect{})
+	if err := db.Create(&deviceReading{TS: ts, Current: 10.2, Location: "SF"}).Error; !errors.Is(err, ErrSubTableModel) {
+		t.Errorf("expect ErrSubTableModel without a subtable name got %v", err)
+	}
+	err := db.Create([]deviceReading{
+		{TS: ts, Current: 10.2, Device: "d1001", Location: "SF"},
+		{TS: ts.Add(time.Second), Current: 10.3, Device: "d1001", Location: "LA"},
+	}).Error
+	if !errors.Is(err, ErrSubTableModel) {
+		t.Errorf("expect ErrSubTableModel for conflicting tags got %v", err)
+	}
+	if err := db.Create(&[]taggedReading{
+		{TS: ts, Current: 10.2, Device: "d1001", Location: "SF"},
+		{TS: ts.Add(time.Second), Current: 10.3, Device: "d1001", Location: "LA"},
+	}).Error; !errors.Is(err, ErrSubTableModel) {
+		t.Errorf("expect ErrSubTableModel for conflicting tag fields got %v", err)
+	}
+	d.AssertExecs(t)
+}
@@ -0,0 +1,64 @@
+package tdengine_gorm
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// selectTags selects the fields of a model with tag fields by name, so the tags are scanned with the data columns
+// and also when the table is a subtable, for which SELECT * returns the data columns only.
+// Queries with a SELECT clause or Select are left as they are.
+func selectTags(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || len(stmt.Selects) > 0 || !hasTagField(stmt.Schema) {
+		return
+	}
+	if _, ok := stmt.Clauses["SELECT"]; ok {
+		return
+	}
+	omits := make(map[string]bool, len(stmt.Omits))
+	for _, name := range stmt.Omits {
+		omits[lookUpDBName(stmt, name)] = true
+	}
+	columns := make([]clause.Column, 0, len(stmt.Schema.DBNames))
+	for _, name := range stmt.Schema.DBNames {
+		if field := stmt.Schema.FieldsByDBName[name]; field.Readable && !omits[name] {
+			columns = append(columns, clause.Column{Name: name})
+		}
+	}
+	stmt.AddClause(clause.Select{Columns: columns})
+}
+
+// withoutTags removes the tag columns from the created values, tags are set by USING or CREATE TABLE, not by INSERT.
+func withoutTags(stmt *gorm.Statement, values clause.Values) clause.Values {
+	var keep []int
+	for i, column := range values.Columns {
+		if field := lookUpField(stmt, column.Name); field == nil || !isTagField(field) {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(values.Columns) {
+		return values
+	}
+	result := clause.Values{Columns: make([]clause.Column, len(keep)), Values: make([][]interface{}, len(values.Values))}
+	for i, k := range keep {
+		result.Columns[i] = values.Columns[k]
+	}
+	for j, row := range values.Values {
+		result.Values[j] = make([]interface{}, len(keep))
+		for i, k := range keep {
+			result.Values[j][i] = row[k]
+		}
+	}
+	return result
+}
+
+func hasTagField(s *schema.Schema) bool {
+	for _, field := range s.Fields {
+		if isTagField(field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package tdengine_gorm
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/using"
+)
+
+func TestTagFields(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	d.Result("SELECT ts,current,voltage,location,group_id FROM d1001 WHERE d1001.location = 'SF' AND d1001.group_id = 2",
+		[]string{"ts", "current", "voltage", "location", "group_id"},
+		[]driver.Value{ts, 10.2, int32(219), "SF", int32(2)},
+	)
+	var meters []meter
+	if err := db.Table("d1001").Where(&meter{Location: "SF", GroupID: 2}).Find(&meters).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(meters) != 1 || meters[0] != (meter{TS: ts, Current: 10.2, Voltage: 219, Location: "SF", GroupID: 2}) {
+		t.Errorf("unexpected meters %+v", meters)
+	}
+
+	d.Result("SELECT ts,current,voltage,location FROM meters", []string{"ts", "current", "voltage", "location"})
+	if err := db.Omit("GroupID").Find(&meters).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.Result("SELECT ts,location FROM meters", []string{"ts", "location"})
+	if err := db.Select("ts", "location").Find(&meters).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	err := db.Table("d1001").Clauses(using.SetUsing("meters", map[string]interface{}{"location": "SF", "group_id": 2})).
+		Create(&[]meter{{TS: ts, Current: 10.2, Voltage: 219, Location: "SF", GroupID: 2}, {TS: ts.Add(time.Second), Current: 10.3, Voltage: 218, Location: "SF", GroupID: 2}}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t, "INSERT INTO d1001 USING meters(group_id,location) TAGS(2,'SF') (ts,current,voltage) VALUES ('2021-08-11T09:43:00Z',10.2,219),('2021-08-11T09:43:01Z',10.3,218)")
+}
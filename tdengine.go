@@ -5,21 +5,55 @@ import (
 	"errors"
 	"fmt"
 	_ "github.com/taosdata/driver-go/v2/taosSql"
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"github.com/taosdata/tdengine_gorm/clause/insert"
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
+	"strings"
+	"time"
 )
 
 // DriverName is the default driver name for TDengine.
 const DriverName = "taosSql"
 
+// createClauses are the clauses of INSERT, ON CONFLICT is checked by the create callback and not written.
+var createClauses = []string{"CREATE TABLE", "INSERT", "USING", "VALUES"}
+
 type Dialect struct {
 	DriverName string
 	DSN        string
 	Conn       gorm.ConnPool
+	// MaxSQLLength is the byte limit of an INSERT statement, Create splits rows into several INSERTs under it.
+	// 0 uses DefaultMaxSQLLength.
+	MaxSQLLength int
+	// DetectMaxSQLLength reads MaxSQLLength from SHOW VARIABLES when the connection is opened.
+	DetectMaxSQLLength bool
+	// InsertMode selects how Create sends rows, InsertStmt binds them through StmtConn.
+	InsertMode InsertMode
+	// StmtConn executes the prepared INSERT statements of InsertStmt, see OpenStmtConn.
+	StmtConn StmtConn
+	// MaxStmtRows is the number of rows InsertStmt binds in one batch, Create splits rows into several batches.
+	// 0 uses DefaultMaxStmtRows.
+	MaxStmtRows int
+	// QuoteMode selects the identifiers QuoteTo wraps in backticks, QuoteNone writes them as they are.
+	QuoteMode QuoteMode
+	// Precision is the timestamp precision of the database, "" is milliseconds.
+	Precision database.Precision
+	// DetectPrecision reads Precision of the current database from SHOW DATABASES when the connection is opened.
+	DetectPrecision bool
+	// EpochTime writes time.Time as an epoch integer in Precision instead of a quoted RFC3339 timestamp.
+	EpochTime bool
+	// Location is the time zone of written RFC3339 timestamps and of the time.Time values scanned by queries,
+	// nil keeps the location of each value.
+	Location *time.Location
+	// Update is the UPDATE option of the database, Save, Updates and OnConflict re-insert rows when it allows updates.
+	Update database.UpdateMode
+	// DetectUpdate reads Update of the current database from SHOW DATABASES when the connection is opened.
+	DetectUpdate bool
 }
 
 func Open(dsn string) gorm.Dialector {
@@ -34,14 +68,17 @@ func (dialect Dialect) Initialize(db *gorm.DB) (err error) {
 	if dialect.DriverName == "" {
 		dialect.DriverName = DriverName
 	}
+	if dialect.InsertMode == InsertStmt && dialect.StmtConn == nil {
+		return ErrStmtConn
+	}
 	db.SkipDefaultTransaction = true
 	db.DisableNestedTransaction = true
 	db.DisableAutomaticPing = true
 	db.DisableForeignKeyConstraintWhenMigrating = true
 	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{
 		LastInsertIDReversed: true,
-		QueryClauses:         []string{"SELECT", "FROM", "WHERE", "WINDOW", "FILL", "GROUP BY", "ORDER BY", "SLIMIT", "LIMIT"},
-		CreateClauses:        []string{"CREATE TABLE", "INSERT", "USING", "VALUES", "ON CONFLICT"},
+		QueryClauses:         []string{"SELECT", "FROM", "WHERE", "PARTITION BY", "RANGE", "EVERY", "WINDOW", "FILL", "GROUP BY", "ORDER BY", "SLIMIT", "LIMIT"},
+		CreateClauses:        createClauses,
 	})
 	if dialect.Conn != nil {
 		db.ConnPool = dialect.Conn
@@ -51,10 +88,58 @@ func (dialect Dialect) Initialize(db *gorm.DB) (err error) {
 			return err
 		}
 	}
+	db.ConnPool = literalConnPool{db.ConnPool}
 	for k, v := range dialect.ClauseBuilders() {
 		db.ClauseBuilders[k] = v
 	}
-	return
+	if dialect.DetectPrecision || dialect.DetectUpdate {
+		options, err := detectDatabase(db)
+		if err != nil {
+			return err
+		}
+		if dialect.DetectPrecision {
+			dialect.Precision = options.Precision
+		}
+		if dialect.DetectUpdate {
+			dialect.Update = options.Update
+		}
+	}
+	// BindVarTo and Explain are called on db.Dialector, it keeps the detected precision
+	db.Dialector = dialect
+	if err = db.Callback().Query().Before("gorm:query").Register("tdengine:select_tags", selectTags); err != nil {
+		return err
+	}
+	if err = db.Callback().Query().Before("gorm:query").Register("tdengine:primary_timestamp", primaryTimestamp); err != nil {
+		return err
+	}
+	if dialect.Location != nil {
+		if err = db.Callback().Query().After("gorm:query").Register("tdengine:location", scanLocation(dialect.Location)); err != nil {
+			return err
+		}
+	}
+	maxSQLLength := dialect.MaxSQLLength
+	if dialect.DetectMaxSQLLength {
+		if maxSQLLength, err = detectMaxSQLLength(db); err != nil {
+			return err
+		}
+	}
+	if maxSQLLength <= 0 {
+		maxSQLLength = DefaultMaxSQLLength
+	}
+	var stmtConn StmtConn
+	if dialect.InsertMode == InsertStmt {
+		stmtConn = dialect.StmtConn
+	}
+	if err = db.Callback().Create().Replace("gorm:create", createCallback(maxSQLLength, stmtConn, dialect.Update)); err != nil {
+		return err
+	}
+	if err = db.Callback().Update().Replace("gorm:update", updateCallback(maxSQLLength, stmtConn, dialect.Update)); err != nil {
+		return err
+	}
+	if err = db.Callback().Delete().Before("gorm:delete").Register("tdengine:primary_timestamp", primaryTimestamp); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Replace("gorm:delete", deleteCallback(maxSQLLength))
 }
 
 func (dialect Dialect) ClauseBuilders() map[string]clause.ClauseBuilder {
@@ -80,7 +165,8 @@ func (dialect Dialect) ClauseBuilders() map[string]clause.ClauseBuilder {
 			if _, ok := c.Expression.(clause.Values); ok {
 				if stmt, ok := builder.(*gorm.Statement); ok {
 					_, containsCreateTable := stmt.Clauses["CREATE TABLE"]
-					if containsCreateTable {
+					_, containsMultiTable := stmt.Clauses["INSERT"].Expression.(insert.MultiTable)
+					if containsCreateTable || containsMultiTable {
 						return
 					}
 				}
@@ -101,22 +187,26 @@ func (dialect Dialect) Migrator(db *gorm.DB) gorm.Migrator {
 	}}, dialect}
 }
 
+// BindVarTo writes v as an escaped literal, v stays in stmt.Vars and the statement is sent without it, see literalConnPool.
 func (dialect Dialect) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
-	switch v.(type) {
-	case string:
-		writer.WriteString("'?'")
-	default:
-		writer.WriteByte('?')
+	literal, err := dialect.Literal(v)
+	if err != nil {
+		if !errors.Is(err, ErrLiteral) {
+			err = fmt.Errorf("%w: %v", ErrLiteral, err)
+		}
+		_ = stmt.AddError(err)
+		return
 	}
+	writer.WriteString(literal)
 }
 
-func (dialect Dialect) QuoteTo(writer clause.Writer, str string) {
-	writer.WriteString(str)
-	return
-}
-
+// Explain writes vars into sql with the same literals as BindVarTo.
 func (dialect Dialect) Explain(sql string, vars ...interface{}) string {
-	return logger.ExplainSQL(sql, nil, "'", vars...)
+	var builder strings.Builder
+	if err := dialect.explain(&builder, sql, vars); err != nil {
+		return logger.ExplainSQL(sql, nil, "'", vars...)
+	}
+	return builder.String()
 }
 
 func (dialect Dialect) DataTypeOf(field *schema.Field) string {
@@ -0,0 +1,188 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/taosdata/driver-go/v2/common"
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// rowTS is the pseudo-column of the timestamp primary key, it orders a query without a model.
+var rowTS = clause.Column{Name: "_rowts", Raw: true}
+
+// precision is the driver-go precision of the dialect.
+func (dialect Dialect) precision() int {
+	switch dialect.Precision {
+	case database.PrecisionMicrosecond:
+		return common.PrecisionMicroSecond
+	case database.PrecisionNanosecond:
+		return common.PrecisionNanoSecond
+	}
+	return common.PrecisionMilliSecond
+}
+
+// timeLiteral writes t as an epoch integer or as a quoted RFC3339 timestamp truncated to the precision.
+func (dialect Dialect) timeLiteral(t time.Time) string {
+	precision := dialect.precision()
+	if dialect.EpochTime {
+		return strconv.FormatInt(common.TimeToTimestamp(t, precision), 10)
+	}
+	if dialect.Location != nil {
+		t = t.In(dialect.Location)
+	}
+	switch precision {
+	case common.PrecisionMilliSecond:
+		t = t.Truncate(time.Millisecond)
+	case common.PrecisionMicroSecond:
+		t = t.Truncate(time.Microsecond)
+	}
+	return "'" + t.Format(time.RFC3339Nano) + "'"
+}
+
+// detectDatabase reads the options of the current database from SHOW DATABASES.
+func detectDatabase(db *gorm.DB) (database.DatabaseOptions, error) {
+	var name string
+	if err := db.Raw("SELECT DATABASE()").Row().Scan(&name); err != nil {
+		return database.DatabaseOptions{}, err
+	}
+	if name == "" {
+		return database.DatabaseOptions{}, errors.New("no database selected to detect the options of")
+	}
+	described, err := db.Migrator().(Migrator).DescribeDatabase(name)
+	if err != nil {
+		return database.DatabaseOptions{}, err
+	}
+	return described.Options, nil
+}
+
+// scanLocation converts the time.Time values scanned by a query to loc.
+func scanLocation(loc *time.Location) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || !db.Statement.ReflectValue.IsValid() {
+			return
+		}
+		inLocation(db.Statement.Schema, db.Statement.ReflectValue, loc)
+	}
+}
+
+func inLocation(s *schema.Schema, rv reflect.Value, loc *time.Location) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			inLocation(s, rv.Elem(), loc)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			inLocation(s, rv.Index(i), loc)
+		}
+	case reflect.Map:
+		if rv.Type().Elem().Kind() != reflect.Interface {
+			return
+		}
+		for _, key := range rv.MapKeys() {
+			if t, ok := rv.MapIndex(key).Interface().(time.Time); ok {
+				rv.SetMapIndex(key, reflect.ValueOf(t.In(loc)))
+			}
+		}
+	case reflect.Struct:
+		if !rv.CanAddr() {
+			return
+		}
+		if t, ok := rv.Addr().Interface().(*time.Time); ok {
+			*t = t.In(loc)
+			return
+		}
+		if s == nil || s.ModelType != rv.Type() {
+			return
+		}
+		for _, field := range s.Fields {
+			if field.DataType != schema.Time {
+				continue
+			}
+			fieldValue := field.ReflectValueOf(rv)
+			if fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil() {
+				continue
+			}
+			if t, ok := reflect.Indirect(fieldValue).Addr().Interface().(*time.Time); ok {
+				*t = t.In(loc)
+			}
+		}
+	}
+}
+
+// timestampField is the primary key of a TDengine table, a time field tagged primaryKey or the first time field.
+// It returns nil for a schema without time fields.
+func timestampField(s *schema.Schema) *schema.Field {
+	if s == nil {
+		return nil
+	}
+	var first *schema.Field
+	for _, field := range s.Fields {
+		if field.DataType != schema.Time || field.DBName == "" || isTagField(field) {
+			continue
+		}
+		if field.PrimaryKey {
+			return field
+		}
+		if first == nil {
+			first = field
+		}
+	}
+	return first
+}
+
+// primaryTimestamp replaces the primary key that First, Last and inline conditions refer to with the timestamp column,
+// gorm would use the first field of the model or fail without a model.
+func primaryTimestamp(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil {
+		return
+	}
+	column := rowTS
+	if field := timestampField(stmt.Schema); field != nil {
+		column = clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	}
+	if c, ok := stmt.Clauses["ORDER BY"]; ok {
+		if orderBy, ok := c.Expression.(clause.OrderBy); ok {
+			columns := make([]clause.OrderByColumn, len(orderBy.Columns))
+			for i, orderByColumn := range orderBy.Columns {
+				if orderByColumn.Column.Name == clause.PrimaryKey {
+					orderByColumn.Column = column
+				}
+				columns[i] = orderByColumn
+			}
+			orderBy.Columns = columns
+			c.Expression = orderBy
+			stmt.Clauses["ORDER BY"] = c
+		}
+	}
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs := make([]clause.Expression, len(where.Exprs))
+			for i, expr := range where.Exprs {
+				switch v := expr.(type) {
+				case clause.Eq:
+					if v.Column == clause.PrimaryColumn {
+						v.Column = column
+					}
+					expr = v
+				case clause.IN:
+					if v.Column == clause.PrimaryColumn {
+						v.Column = column
+					}
+					expr = v
+				}
+				exprs[i] = expr
+			}
+			where.Exprs = exprs
+			c.Expression = where
+			stmt.Clauses["WHERE"] = c
+		}
+	}
+}
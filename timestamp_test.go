@@ -0,0 +1,113 @@
+package tdengine_gorm
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"gorm.io/gorm"
+)
+
+func TestTimeLiteral(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 1500500, time.UTC)
+	tests := []struct {
+		dialect Dialect
+		expect  string
+	}{
+		{Dialect{}, "'2021-08-11T09:43:00.001Z'"},
+		{Dialect{Precision: database.PrecisionMicrosecond}, "'2021-08-11T09:43:00.0015Z'"},
+		{Dialect{Precision: database.PrecisionNanosecond}, "'2021-08-11T09:43:00.0015005Z'"},
+		{Dialect{Location: shanghai}, "'2021-08-11T17:43:00.001+08:00'"},
+		{Dialect{EpochTime: true}, "1628674980001"},
+		{Dialect{EpochTime: true, Precision: database.PrecisionMicrosecond}, "1628674980001500"},
+		{Dialect{EpochTime: true, Precision: database.PrecisionNanosecond}, "1628674980001500500"},
+	}
+	for _, test := range tests {
+		literal, err := test.dialect.Literal(ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if literal != test.expect {
+			t.Errorf("%+v: expect %s got %s", test.dialect, test.expect, literal)
+		}
+		if explained := test.dialect.Explain("SELECT * FROM meters WHERE ts > ?", ts); explained != "SELECT * FROM meters WHERE ts > "+test.expect {
+			t.Errorf("%+v: unexpected explain %s", test.dialect, explained)
+		}
+	}
+}
+
+func TestDetectPrecision(t *testing.T) {
+	db, d := openRecordDB(t, Dialect{})
+	d.Result("SELECT DATABASE()", []string{"database()"}, []driver.Value{"power"})
+	d.Result("SHOW DATABASES", []string{"name", "precision", "update"}, []driver.Value{"log", "ms", int8(0)}, []driver.Value{"power", "us", int8(2)})
+	options, err := detectDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Precision != database.PrecisionMicrosecond {
+		t.Errorf("expect us got %s", options.Precision)
+	}
+	if options.Update != database.UpdatePartial {
+		t.Errorf("expect UPDATE 2 got %d", options.Update.Value())
+	}
+}
+
+func TestScanLocation(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	db, d := openRecordDB(t, Dialect{Location: shanghai, EpochTime: true})
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	d.Result("SELECT * FROM d1001 WHERE ts >= 1628674980000", []string{"ts", "value"}, []driver.Value{ts, int64(1)})
+	var readings []reading
+	if err := db.Where("ts >= ?", ts).Find(&readings).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 1 || readings[0].TS.Location() != shanghai || !readings[0].TS.Equal(ts) {
+		t.Errorf("expect %v in CST got %v", ts, readings)
+	}
+	var rows []map[string]interface{}
+	if err := db.Table("d1001").Where("ts >= ?", ts).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := rows[0]["ts"].(time.Time); got.Location() != shanghai {
+		t.Errorf("expect ts in CST got %v", rows[0]["ts"])
+	}
+}
+
+type currentReading struct {
+	Current float64
+	TS      time.Time
+	Created time.Time
+}
+
+type keyedReading struct {
+	Created time.Time
+	TS      time.Time `gorm:"primaryKey"`
+	Current float64
+}
+
+func TestPrimaryTimestamp(t *testing.T) {
+	db, _ := openRecordDB(t, Dialect{})
+	db = db.Session(&gorm.Session{DryRun: true})
+	tests := []struct {
+		query  *gorm.DB
+		expect string
+	}{
+		{db.First(&currentReading{}), "SELECT * FROM current_readings ORDER BY current_readings.ts LIMIT 1"},
+		{db.Last(&currentReading{}), "SELECT * FROM current_readings ORDER BY current_readings.ts DESC LIMIT 1"},
+		{db.First(&keyedReading{}), "SELECT * FROM keyed_readings ORDER BY keyed_readings.ts LIMIT 1"},
+		{db.Take(&keyedReading{}), "SELECT * FROM keyed_readings LIMIT 1"},
+		{db.Table("d1001").Last(&meter{}), "SELECT ts,current,voltage,location,group_id FROM d1001 ORDER BY d1001.ts DESC LIMIT 1"},
+		{db.Table("d1001").First(&map[string]interface{}{}), "SELECT * FROM d1001 ORDER BY _rowts LIMIT 1"},
+		{db.First(&currentReading{}, 1628675000000), "SELECT * FROM current_readings WHERE current_readings.ts = 1628675000000 ORDER BY current_readings.ts LIMIT 1"},
+	}
+	for _, test := range tests {
+		if test.query.Error != nil {
+			t.Errorf("%s: %v", test.expect, test.query.Error)
+		}
+		if sql := test.query.Statement.SQL.String(); sql != test.expect {
+			t.Errorf("expect %s got %s", test.expect, sql)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUpsert is returned by Save, Updates and OnConflict when the write cannot be done by re-inserting rows.
+var ErrUpsert = errors.New("upsert not supported")
+
+// checkUpdate reports whether re-inserting a row with partial columns is an update in the update mode.
+func checkUpdate(update database.UpdateMode, partial bool) error {
+	switch update {
+	case database.UpdateAll:
+		if partial {
+			return fmt.Errorf("%w: UPDATE 1 replaces the whole row, writing some columns needs UPDATE 2", ErrUpsert)
+		}
+		return nil
+	case database.UpdatePartial:
+		return nil
+	case database.UpdateDisabled:
+		return fmt.Errorf("%w: the database is created with UPDATE 0 and discards rows with an existing timestamp", ErrUpsert)
+	}
+	return fmt.Errorf("%w: set Dialect.Update or Dialect.DetectUpdate to the UPDATE option of the database", ErrUpsert)
+}
+
+// checkOnConflict checks the ON CONFLICT clause of a create against the update mode and removes it,
+// the rows are re-inserted as they are. DO NOTHING is the behaviour of UPDATE 0,
+// DO UPDATE has to update every inserted column from the inserted row, a subset is rejected
+// as the new rows would miss the other columns, select the updated columns with Select instead.
+// Inserting some of the data columns needs UPDATE 2 as UPDATE 1 sets the other columns to NULL.
+func checkOnConflict(stmt *gorm.Statement, values clause.Values, update database.UpdateMode) error {
+	c, ok := stmt.Clauses["ON CONFLICT"]
+	if !ok {
+		return nil
+	}
+	delete(stmt.Clauses, "ON CONFLICT")
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	if !ok {
+		return fmt.Errorf("%w: ON CONFLICT %T", ErrUpsert, c.Expression)
+	}
+	if len(onConflict.Where.Exprs) > 0 || len(onConflict.TargetWhere.Exprs) > 0 || onConflict.OnConstraint != "" {
+		return fmt.Errorf("%w: rows conflict on the timestamp only, ON CONFLICT conditions and constraints are not supported", ErrUpsert)
+	}
+	if onConflict.DoNothing {
+		if update != database.UpdateDisabled {
+			return fmt.Errorf("%w: DO NOTHING needs a database created with UPDATE 0", ErrUpsert)
+		}
+		return nil
+	}
+	ts := timestampField(stmt.Schema)
+	partial := false
+	if stmt.Schema != nil {
+		inserted := 0
+		for _, column := range values.Columns {
+			if ts == nil || column.Name != ts.DBName {
+				inserted++
+			}
+		}
+		partial = inserted < dataColumnCount(stmt.Schema, ts)
+	}
+	if onConflict.UpdateAll {
+		return checkUpdate(update, partial)
+	}
+	updated := map[string]bool{}
+	for _, assignment := range onConflict.DoUpdates {
+		if excluded, ok := assignment.Value.(clause.Column); !ok || excluded.Table != "excluded" || excluded.Name != assignment.Column.Name {
+			return fmt.Errorf("%w: %s can only be updated to the inserted value", ErrUpsert, assignment.Column.Name)
+		}
+		updated[lookUpDBName(stmt, assignment.Column.Name)] = true
+	}
+	for _, column := range values.Columns {
+		if !updated[column.Name] && (ts == nil || column.Name != ts.DBName) {
+			return fmt.Errorf("%w: DO UPDATE does not update %s, a re-insert writes every inserted column", ErrUpsert, column.Name)
+		}
+	}
+	return checkUpdate(update, partial)
+}
+
+// dataColumnCount is the number of columns of s that are neither the timestamp nor tags.
+func dataColumnCount(s *schema.Schema, ts *schema.Field) int {
+	count := 0
+	for _, field := range s.Fields {
+		if field.DBName != "" && field != ts && !isTagField(field) {
+			count++
+		}
+	}
+	return count
+}
+
+// updateCallback replaces the gorm update callback, Save and Updates re-insert the row at the timestamp of the model,
+// which the database applies as an update. Writing some of the data columns needs UPDATE 2.
+func updateCallback(maxSQLLength int, stmtConn StmtConn, update database.UpdateMode) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil {
+			return
+		}
+		stmt := db.Statement
+		if stmt.Schema == nil {
+			db.AddError(fmt.Errorf("%w: updates need a model", ErrUpsert))
+			return
+		}
+		if _, ok := stmt.Clauses["WHERE"]; ok {
+			db.AddError(fmt.Errorf("%w: rows are updated by the timestamp of the model, conditions are not supported", ErrUpsert))
+			return
+		}
+		values, partial, err := upsertValues(stmt)
+		if err == nil {
+			err = checkUpdate(update, partial)
+		}
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		stmt.BuildClauses = createClauses
+		stmt.AddClauseIfNotExists(clause.Insert{})
+		createRows(db, values, maxSQLLength, stmtConn)
+	}
+}
+
+// upsertValues is the row written by an update: the timestamp and the updated data columns,
+// partial is true when some data columns are not written.
+func upsertValues(stmt *gorm.Statement) (clause.Values, bool, error) {
+	ts := timestampField(stmt.Schema)
+	if ts == nil {
+		return clause.Values{}, false, fmt.Errorf("%w: %s has no timestamp field", ErrUpsert, stmt.Schema.Name)
+	}
+	// the update callbacks set ReflectValue to the model when it is not the updated value
+	model := stmt.ReflectValue
+	row := reflect.Indirect(reflect.ValueOf(stmt.Dest))
+	if row.Kind() == reflect.Struct && row.Type() == stmt.Schema.ModelType {
+		model = row
+	} else if row.Kind() != reflect.Map {
+		return clause.Values{}, false, fmt.Errorf("%w: updating %s", ErrUpsert, row.Type())
+	}
+	if model.Kind() != reflect.Struct || model.Type() != stmt.Schema.ModelType {
+		return clause.Values{}, false, fmt.Errorf("%w: updates need a model with the timestamp of the row", ErrUpsert)
+	}
+	tsValue, isZero := ts.ValueOf(model)
+	if isZero {
+		return clause.Values{}, false, fmt.Errorf("%w: %s of the updated row is zero", ErrUpsert, ts.Name)
+	}
+
+	values := clause.Values{Columns: []clause.Column{{Name: ts.DBName}}, Values: [][]interface{}{{tsValue}}}
+	add := func(field *schema.Field, value interface{}) {
+		values.Columns = append(values.Columns, clause.Column{Name: field.DBName})
+		values.Values[0] = append(values.Values[0], value)
+	}
+	if row.Kind() == reflect.Map {
+		updates := make(map[string]interface{}, row.Len())
+		keys := make([]string, 0, row.Len())
+		for iter := row.MapRange(); iter.Next(); {
+			key := fmt.Sprint(iter.Key().Interface())
+			updates[key] = iter.Value().Interface()
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field := stmt.Schema.LookUpField(key)
+			_, isExpr := updates[key].(clause.Expression)
+			switch {
+			case field == nil || field.DBName == "":
+				return clause.Values{}, false, fmt.Errorf("%w: %s is not a column of %s", ErrUpsert, key, stmt.Schema.Name)
+			case isTagField(field):
+				return clause.Values{}, false, fmt.Errorf("%w: tag %s is not written by INSERT", ErrUpsert, key)
+			case isExpr:
+				// a re-insert writes values, an expression such as gorm.Expr("value + ?", 1) cannot read the old row
+				return clause.Values{}, false, fmt.Errorf("%w: %s is updated with an expression", ErrUpsert, key)
+			case field != ts:
+				add(field, updates[key])
+			}
+		}
+	} else {
+		selected, restricted := stmt.SelectAndOmitColumns(false, true)
+		for _, name := range stmt.Schema.DBNames {
+			field := stmt.Schema.FieldsByDBName[name]
+			if field == ts {
+				continue
+			}
+			value, isZero := field.ValueOf(row)
+			v, ok := selected[name]
+			if written := (ok && v) || (!ok && !restricted && !isZero); !written {
+				continue
+			}
+			if isTagField(field) {
+				// Save selects every field, zero tags are left out
+				if !isZero {
+					return clause.Values{}, false, fmt.Errorf("%w: tag %s is not written by INSERT, omit it", ErrUpsert, field.Name)
+				}
+				continue
+			}
+			add(field, value)
+		}
+	}
+	return values, len(values.Columns)-1 < dataColumnCount(stmt.Schema, ts), nil
+}
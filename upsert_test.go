@@ -0,0 +1,139 @@
+package tdengine_gorm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taosdata/tdengine_gorm/clause/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestUpsertSave(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	db, d := openRecordDB(t, Dialect{Update: database.UpdateAll})
+	if err := db.Save(&reading{TS: ts, Value: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Save(&[]reading{{TS: ts, Value: 2}, {TS: ts.Add(time.Second), Value: 3}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("d1001").Model(&meter{TS: ts}).Updates(map[string]interface{}{"current": 10.5, "voltage": 220}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:00Z',1)",
+		"INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:00Z',2),('2021-08-11T09:43:01Z',3)",
+		"INSERT INTO d1001 (ts,current,voltage) VALUES ('2021-08-11T09:43:00Z',10.5,220)",
+	)
+
+	if err := db.Table("d1001").Model(&meter{TS: ts}).Update("current", 10.6).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert for a partial write with UPDATE 1 got %v", err)
+	}
+	if err := db.Model(&reading{TS: ts}).Where("value > ?", 1).Update("value", 2).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert for conditions got %v", err)
+	}
+	if err := db.Save(&reading{Value: 1}).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert without timestamp got %v", err)
+	}
+	if err := db.Table("d1001").Model(&meter{TS: ts}).Update("location", "LA").Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert for a tag got %v", err)
+	}
+
+	db, d = openRecordDB(t, Dialect{Update: database.UpdatePartial})
+	if err := db.Table("d1001").Model(&meter{TS: ts}).Update("current", 10.6).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("d1001").Updates(&meter{TS: ts, Voltage: 221}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("d1001").Omit("location").Updates(&meter{TS: ts, Voltage: 222, Location: "SF"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 (ts,current) VALUES ('2021-08-11T09:43:00Z',10.6)",
+		"INSERT INTO d1001 (ts,voltage) VALUES ('2021-08-11T09:43:00Z',221)",
+		"INSERT INTO d1001 (ts,voltage) VALUES ('2021-08-11T09:43:00Z',222)",
+	)
+	if err := db.Table("d1001").Updates(&meter{TS: ts, Voltage: 221, Location: "SF"}).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert for a tag got %v", err)
+	}
+	if err := db.Table("d1001").Save(&meter{TS: ts, Current: 10.2, Voltage: 221, GroupID: 2}).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert for a tag in Save got %v", err)
+	}
+	if err := db.Table("d1001").Model(&meter{TS: ts}).Update("current", gorm.Expr("current + ?", 1)).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert for gorm.Expr got %v", err)
+	}
+	if err := db.Table("d1001").Model(&meter{TS: ts}).Updates(map[string]interface{}{"voltage": clause.Expr{SQL: "voltage * 2"}}).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert for clause.Expr got %v", err)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 (ts,current) VALUES ('2021-08-11T09:43:00Z',10.6)",
+		"INSERT INTO d1001 (ts,voltage) VALUES ('2021-08-11T09:43:00Z',221)",
+		"INSERT INTO d1001 (ts,voltage) VALUES ('2021-08-11T09:43:00Z',222)",
+	)
+
+	for _, update := range []database.UpdateMode{0, database.UpdateDisabled} {
+		db, _ = openRecordDB(t, Dialect{Update: update})
+		if err := db.Save(&reading{TS: ts, Value: 1}).Error; !errors.Is(err, ErrUpsert) {
+			t.Errorf("UPDATE %d: expect ErrUpsert got %v", update.Value(), err)
+		}
+		if err := db.Save(&[]reading{{TS: ts, Value: 1}}).Error; !errors.Is(err, ErrUpsert) {
+			t.Errorf("UPDATE %d: expect ErrUpsert got %v", update.Value(), err)
+		}
+	}
+}
+
+func TestUpsertOnConflict(t *testing.T) {
+	ts := time.Date(2021, 8, 11, 9, 43, 0, 0, time.UTC)
+	db, d := openRecordDB(t, Dialect{Update: database.UpdateDisabled})
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&reading{TS: ts, Value: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t, "INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:00Z',1)")
+	if err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&reading{TS: ts, Value: 1}).Error; !errors.Is(err, ErrUpsert) {
+		t.Errorf("expect ErrUpsert got %v", err)
+	}
+
+	db, d = openRecordDB(t, Dialect{Update: database.UpdateAll})
+	if err := db.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"value"})}).Create(&reading{TS: ts, Value: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("d1001").Clauses(clause.OnConflict{UpdateAll: true}).Create(&meter{TS: ts, Current: 10.2, Voltage: 220}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t,
+		"INSERT INTO d1001 (ts,value) VALUES ('2021-08-11T09:43:00Z',2)",
+		"INSERT INTO d1001 (ts,current,voltage) VALUES ('2021-08-11T09:43:00Z',10.2,220)",
+	)
+	for _, onConflict := range []clause.OnConflict{
+		{DoUpdates: clause.AssignmentColumns([]string{"current"})},
+		{UpdateAll: true},
+	} {
+		// UPDATE 1 would set voltage to NULL
+		err := db.Table("d1001").Select("ts", "current").Clauses(onConflict).Create(&meter{TS: ts, Current: 10.2}).Error
+		if !errors.Is(err, ErrUpsert) {
+			t.Errorf("%+v: expect ErrUpsert for a partial insert with UPDATE 1 got %v", onConflict, err)
+		}
+	}
+
+	db, d = openRecordDB(t, Dialect{Update: database.UpdatePartial})
+	if err := db.Table("d1001").Select("ts", "current").Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"current"})}).
+		Create(&meter{TS: ts, Current: 10.2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.AssertExecs(t, "INSERT INTO d1001 (ts,current) VALUES ('2021-08-11T09:43:00Z',10.2)")
+
+	db, _ = openRecordDB(t, Dialect{Update: database.UpdateAll})
+	for _, onConflict := range []clause.OnConflict{
+		{DoNothing: true},
+		{DoUpdates: clause.AssignmentColumns([]string{"current"})},
+		{DoUpdates: clause.Assignments(map[string]interface{}{"current": 0})},
+		{Columns: []clause.Column{{Name: "ts"}}, Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "current > 0"}}}, UpdateAll: true},
+	} {
+		if err := db.Table("d1001").Clauses(onConflict).Create(&meter{TS: ts, Current: 10.2}).Error; !errors.Is(err, ErrUpsert) {
+			t.Errorf("%+v: expect ErrUpsert got %v", onConflict, err)
+		}
+	}
+}